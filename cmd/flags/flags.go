@@ -41,6 +41,48 @@ var (
 		Value: 1000,
 		Usage: "batch size for reading history record from the database",
 	}
+	SnapshotDirFlag = &cli.StringFlag{
+		Name:  "dir",
+		Usage: "the directory of the snapshot",
+	}
+	LevelDBFileFlag = &cli.StringFlag{
+		Name:  "leveldb",
+		Usage: "the leveldb file of the trees",
+	}
+	FromConfigFlag = &cli.StringFlag{
+		Name:  "from",
+		Usage: "the config file of the source tree db",
+	}
+	ToConfigFlag = &cli.StringFlag{
+		Name:  "to",
+		Usage: "the config file of the destination tree db",
+	}
+	CheckpointFlag = &cli.StringFlag{
+		Name:  "checkpoint",
+		Value: "migrate-treedb.checkpoint",
+		Usage: "the file of the progress to resume from",
+	}
+	KeystoreFlag = &cli.StringFlag{
+		Name:  "keystore",
+		Usage: "the encrypted keystore file",
+	}
+	PasswordFileFlag = &cli.StringFlag{
+		Name:  "password",
+		Usage: "the file containing the password of the keystore",
+	}
+	TokenFileFlag = &cli.StringFlag{
+		Name:  "token",
+		Usage: "the file containing the bearer token shared with the sender",
+	}
+	RollupAddressFlag = &cli.StringFlag{
+		Name:  "rollup-address",
+		Usage: "the address of the rollup contract, only the txs to it are signed",
+	}
+	ListenAddrFlag = &cli.StringFlag{
+		Name:  "addr",
+		Value: "127.0.0.1:8550",
+		Usage: "the listening address",
+	}
 	PProfEnabledFlag = &cli.BoolFlag{
 		Name:  "pprof",
 		Value: false,
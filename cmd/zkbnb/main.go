@@ -18,9 +18,17 @@ import (
 	"github.com/bnb-chain/zkbnb/service/monitor"
 	"github.com/bnb-chain/zkbnb/service/prover"
 	"github.com/bnb-chain/zkbnb/service/sender"
+	"github.com/bnb-chain/zkbnb/service/sender/signer"
 	"github.com/bnb-chain/zkbnb/service/witness"
+	"github.com/bnb-chain/zkbnb/tools/collectionmigration"
 	"github.com/bnb-chain/zkbnb/tools/dbinitializer"
+	"github.com/bnb-chain/zkbnb/tools/migratetreedb"
 	"github.com/bnb-chain/zkbnb/tools/recovery"
+	"github.com/bnb-chain/zkbnb/tools/snapshot"
+	"github.com/bnb-chain/zkbnb/tools/treeimport"
+	"github.com/bnb-chain/zkbnb/tools/treeusage"
+	"github.com/bnb-chain/zkbnb/tools/verifystate"
+	"github.com/bnb-chain/zkbnb/tools/witnessmigration"
 
 	"net/http"
 )
@@ -68,6 +76,26 @@ func main() {
 					startMetricsServer(cCtx)
 					return prover.Run(cCtx.String(flags.ConfigFlag.Name))
 				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "check",
+						Usage: "Check a block witness against the circuit without generating a proof",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) ||
+								!cCtx.IsSet(flags.BlockHeightFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return prover.CheckWitness(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+							)
+						},
+					},
+				},
 			},
 			{
 				Name:  "witness",
@@ -162,6 +190,35 @@ func main() {
 					startMetricsServer(cCtx)
 					return sender.Run(cCtx.String(flags.ConfigFlag.Name))
 				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "remote-signer",
+						Usage: "Serve the remote signing protocol with a keystore, it stands in for a signing service",
+						Flags: []cli.Flag{
+							flags.KeystoreFlag,
+							flags.PasswordFileFlag,
+							flags.TokenFileFlag,
+							flags.RollupAddressFlag,
+							flags.ListenAddrFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.KeystoreFlag.Name) ||
+								!cCtx.IsSet(flags.PasswordFileFlag.Name) ||
+								!cCtx.IsSet(flags.TokenFileFlag.Name) ||
+								!cCtx.IsSet(flags.RollupAddressFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return signer.ServeRemoteSigner(
+								cCtx.String(flags.ListenAddrFlag.Name),
+								cCtx.String(flags.KeystoreFlag.Name),
+								cCtx.String(flags.PasswordFileFlag.Name),
+								cCtx.String(flags.TokenFileFlag.Name),
+								cCtx.String(flags.RollupAddressFlag.Name),
+							)
+						},
+					},
+				},
 			},
 			{
 				Name:  "apiserver",
@@ -211,6 +268,139 @@ func main() {
 							)
 						},
 					},
+					{
+						Name:  "migrate-witness",
+						Usage: "Re-encode json block witnesses with the binary encoding",
+						Flags: []cli.Flag{
+							flags.DSNFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.DSNFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return witnessmigration.MigrateBlockWitness(
+								cCtx.String(flags.DSNFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "migrate-collection",
+						Usage: "Fill the collection table from the txs, the committer must be stopped",
+						Flags: []cli.Flag{
+							flags.DSNFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.DSNFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return collectionmigration.MigrateCollections(
+								cCtx.String(flags.DSNFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "snapshot",
+				Usage: "State snapshot tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Export the state at a verified block height, the latest verified height by default",
+						Flags: []cli.Flag{
+							flags.DSNFlag,
+							flags.SnapshotDirFlag,
+							flags.BlockHeightFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.DSNFlag.Name) ||
+								!cCtx.IsSet(flags.SnapshotDirFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return snapshot.ExportSnapshot(
+								cCtx.String(flags.DSNFlag.Name),
+								cCtx.String(flags.SnapshotDirFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "import",
+						Usage: "Verify a snapshot and import it into an empty database",
+						Flags: []cli.Flag{
+							flags.DSNFlag,
+							flags.SnapshotDirFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.DSNFlag.Name) ||
+								!cCtx.IsSet(flags.SnapshotDirFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return snapshot.ImportSnapshot(
+								cCtx.String(flags.DSNFlag.Name),
+								cCtx.String(flags.SnapshotDirFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "verify-state",
+				Usage: "Verify the state in the tree db and redis cache against the database",
+				Flags: []cli.Flag{
+					flags.ConfigFlag,
+					flags.ServiceNameFlag,
+					flags.BatchSizeFlag,
+				},
+				Action: func(cCtx *cli.Context) error {
+					if !cCtx.IsSet(flags.ServiceNameFlag.Name) ||
+						!cCtx.IsSet(flags.ConfigFlag.Name) {
+						return cli.ShowSubcommandHelp(cCtx)
+					}
+
+					return verifystate.VerifyState(
+						cCtx.String(flags.ConfigFlag.Name),
+						cCtx.String(flags.ServiceNameFlag.Name),
+						cCtx.Int(flags.BatchSizeFlag.Name),
+					)
+				},
+			},
+			{
+				Name:  "migrate-treedb",
+				Usage: "Migrate the trees of a service from a tree db to another",
+				Flags: []cli.Flag{
+					flags.FromConfigFlag,
+					flags.ToConfigFlag,
+					flags.ServiceNameFlag,
+					flags.CheckpointFlag,
+					flags.BatchSizeFlag,
+				},
+				Action: func(cCtx *cli.Context) error {
+					if !cCtx.IsSet(flags.FromConfigFlag.Name) ||
+						!cCtx.IsSet(flags.ToConfigFlag.Name) ||
+						!cCtx.IsSet(flags.ServiceNameFlag.Name) {
+						return cli.ShowSubcommandHelp(cCtx)
+					}
+
+					return migratetreedb.MigrateTreeDB(
+						cCtx.String(flags.FromConfigFlag.Name),
+						cCtx.String(flags.ToConfigFlag.Name),
+						cCtx.String(flags.ServiceNameFlag.Name),
+						cCtx.String(flags.CheckpointFlag.Name),
+						cCtx.Int(flags.BatchSizeFlag.Name),
+					)
 				},
 			},
 			{
@@ -241,6 +431,46 @@ func main() {
 							return nil
 						},
 					},
+					{
+						Name:  "usage",
+						Usage: "Report the kept versions and the storage of the trees of a service",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.ServiceNameFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ServiceNameFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return treeusage.ReportTreeUsage(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.ServiceNameFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "import-leveldb",
+						Usage: "Import the trees of a leveldb file into an empty badgerdb",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.LevelDBFileFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.LevelDBFileFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return treeimport.ImportLevelDB(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.LevelDBFileFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
 				},
 			},
 		},
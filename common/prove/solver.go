@@ -0,0 +1,62 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prove
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	cryptoTypes "github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// NewBlockConstraints returns the empty block circuit for the given block size,
+// which is used both to compile the r1cs and to check a witness against the circuit.
+func NewBlockConstraints(blockSize int) *circuit.BlockConstraints {
+	var blockConstraints circuit.BlockConstraints
+	blockConstraints.TxsCount = blockSize
+	blockConstraints.Txs = make([]circuit.TxConstraints, blockConstraints.TxsCount)
+	for i := 0; i < blockConstraints.TxsCount; i++ {
+		blockConstraints.Txs[i] = circuit.GetZeroTxConstraint()
+	}
+	blockConstraints.GasAssetIds = types.GasAssets[:]
+	blockConstraints.GasAccountIndex = types.GasAccount
+	blockConstraints.Gas = circuit.GetZeroGasConstraints(types.GasAssets[:])
+	return &blockConstraints
+}
+
+// CheckBlockSatisfiability runs the block witness through gnark's test engine instead of
+// the groth16 prover. It needs neither the compiled r1cs nor the proving key, and the
+// returned error names the failing assertion together with the circuit stack leading to it.
+func CheckBlockSatisfiability(cBlock *circuit.Block) error {
+	blockWitness, err := circuit.SetBlockWitness(cBlock)
+	if err != nil {
+		return fmt.Errorf("failed to set block witness: %v", err)
+	}
+	blockConstraints := NewBlockConstraints(len(cBlock.Txs))
+	err = test.IsSolved(blockConstraints, &blockWitness, ecc.BN254, backend.GROTH16,
+		backend.WithHints(cryptoTypes.Keccak256))
+	if err != nil {
+		return fmt.Errorf("block %d is not satisfiable: %v", cBlock.BlockNumber, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prove
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+)
+
+// The first byte of a binary block witness is the version of its encoding.
+const (
+	// WitnessEncodingGobGzip is a gob encoded circuit.Block compressed by gzip.
+	WitnessEncodingGobGzip byte = 1
+)
+
+// EncodeBlockWitness encodes the block witness with the latest binary encoding.
+func EncodeBlockWitness(cBlock *circuit.Block) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(WitnessEncodingGobGzip)
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	err = gob.NewEncoder(zw).Encode(cBlock)
+	if err != nil {
+		return nil, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeBlockWitness decodes a binary block witness of any known encoding version.
+func DecodeBlockWitness(data []byte) (*circuit.Block, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty block witness")
+	}
+	switch data[0] {
+	case WitnessEncodingGobGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return nil, err
+		}
+		//nolint:errcheck
+		defer zr.Close()
+		var cBlock *circuit.Block
+		err = gob.NewDecoder(zr).Decode(&cBlock)
+		if err != nil {
+			return nil, err
+		}
+		return cBlock, nil
+	default:
+		return nil, fmt.Errorf("unknown block witness encoding version %d", data[0])
+	}
+}
+
+// ParseBlockWitness reads the circuit block from a block witness row, it accepts both
+// the binary encoding and the legacy json encoding.
+func ParseBlockWitness(witness *blockwitness.BlockWitness) (*circuit.Block, error) {
+	if len(witness.WitnessBinary) > 0 {
+		return DecodeBlockWitness(witness.WitnessBinary)
+	}
+	var cBlock *circuit.Block
+	err := json.Unmarshal([]byte(witness.WitnessData), &cBlock)
+	if err != nil {
+		return nil, err
+	}
+	return cBlock, nil
+}
@@ -0,0 +1,336 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prove
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	cryptoTypes "github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestBlockWitnessEncoding(t *testing.T) {
+	cBlock := &circuit.Block{
+		BlockNumber:     3,
+		CreatedAt:       1665000000000,
+		OldStateRoot:    tree.NilStateRoot,
+		NewStateRoot:    tree.NilStateRoot,
+		BlockCommitment: make([]byte, 32),
+		Txs: []*circuit.Tx{
+			circuit.EmptyTx(tree.NilStateRoot),
+			circuit.EmptyTx(tree.NilStateRoot),
+		},
+		Gas: &circuit.Gas{
+			AccountInfoBefore: cryptoTypes.EmptyGasAccount(1, tree.NilAccountAssetRoot),
+		},
+	}
+	for i := range cBlock.Gas.MerkleProofsAccountBefore {
+		cBlock.Gas.MerkleProofsAccountBefore[i] = make([]byte, 32)
+	}
+	expected := marshalCircuitWitness(t, cBlock)
+	jsonData, err := json.Marshal(cBlock)
+	assert.NoError(t, err)
+
+	bz, err := EncodeBlockWitness(cBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, WitnessEncodingGobGzip, bz[0])
+	assert.Less(t, len(bz), len(jsonData))
+
+	// binary encoded witness
+	decoded, err := ParseBlockWitness(&blockwitness.BlockWitness{WitnessBinary: bz})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, marshalCircuitWitness(t, decoded))
+
+	// legacy json encoded witness
+	decoded, err = ParseBlockWitness(&blockwitness.BlockWitness{WitnessData: string(jsonData)})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, marshalCircuitWitness(t, decoded))
+
+	bz[0] = 0xff
+	_, err = DecodeBlockWitness(bz)
+	assert.Error(t, err)
+}
+
+func TestConstructedBlockWitnessEncoding(t *testing.T) {
+	cBlock := constructTestBlockWitness(t)
+	expected := marshalCircuitWitness(t, cBlock)
+	jsonData, err := json.Marshal(cBlock)
+	require.NoError(t, err)
+	bz, err := EncodeBlockWitness(cBlock)
+	require.NoError(t, err)
+
+	binaryDecoded, err := ParseBlockWitness(&blockwitness.BlockWitness{WitnessBinary: bz})
+	require.NoError(t, err)
+	jsonDecoded, err := ParseBlockWitness(&blockwitness.BlockWitness{WitnessData: string(jsonData)})
+	require.NoError(t, err)
+
+	assertFieldsEqual(t, "Block", jsonDecoded, binaryDecoded)
+	require.Len(t, binaryDecoded.Txs, len(cBlock.Txs))
+	for i := range cBlock.Txs {
+		assert.NotNil(t, binaryDecoded.Txs[i].Signature)
+		assertFieldsEqual(t, fmt.Sprintf("Txs[%d]", i), jsonDecoded.Txs[i], binaryDecoded.Txs[i])
+	}
+	assertFieldsEqual(t, "Gas", jsonDecoded.Gas, binaryDecoded.Gas)
+	assert.Equal(t, expected, marshalCircuitWitness(t, binaryDecoded))
+	assert.Equal(t, expected, marshalCircuitWitness(t, jsonDecoded))
+}
+
+// assertFieldsEqual compares the fields of two witness structs one by one, gob decodes empty
+// slices as nil while json decodes them as empty ones, which are the same to the circuit.
+func assertFieldsEqual(t *testing.T, name string, expected, actual interface{}) {
+	expectedValue := reflect.Indirect(reflect.ValueOf(expected))
+	actualValue := reflect.Indirect(reflect.ValueOf(actual))
+	for i := 0; i < expectedValue.NumField(); i++ {
+		if !witnessValuesEqual(expectedValue.Field(i), actualValue.Field(i)) {
+			expectedField, _ := json.Marshal(expectedValue.Field(i).Interface())
+			actualField, _ := json.Marshal(actualValue.Field(i).Interface())
+			assert.Fail(t, "witness field mismatch", "%s.%s: expected %s, actual %s",
+				name, expectedValue.Type().Field(i).Name, expectedField, actualField)
+		}
+	}
+}
+
+func witnessValuesEqual(a, b reflect.Value) bool {
+	if a.Type() == reflect.TypeOf(big.Int{}) {
+		x, y := a.Interface().(big.Int), b.Interface().(big.Int)
+		return x.Cmp(&y) == 0
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return witnessValuesEqual(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !witnessValuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !witnessValuesEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
+
+// witnessTestModel serves the accounts of the test block, the gas account has no history.
+type witnessTestModel struct {
+	account.AccountModel
+	account.AccountHistoryModel
+	nft.L2NftHistoryModel
+	accounts map[int64]*account.Account
+}
+
+func (m *witnessTestModel) GetConfirmedAccountByIndex(accountIndex int64) (*account.Account, error) {
+	a, ok := m.accounts[accountIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	accountCopy := *a
+	return &accountCopy, nil
+}
+
+func (m *witnessTestModel) GetValidAccountCount(height int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *witnessTestModel) GetLatestAccountHistory(accountIndex, height int64) (*account.AccountHistory, error) {
+	return &account.AccountHistory{
+		AccountIndex: accountIndex,
+		AssetInfo:    types.EmptyAccountAssetInfo,
+		AssetRoot:    common.Bytes2Hex(tree.NilAccountAssetRoot),
+	}, nil
+}
+
+func (m *witnessTestModel) GetLatestNftsCountByBlockHeight(height int64) (int64, error) {
+	return 0, nil
+}
+
+// constructTestBlockWitness builds the witness of a block with a transfer, a mint and an atomic
+// match of signed txs by the witness helper.
+func constructTestBlockWitness(t *testing.T) *circuit.Block {
+	keys := make(map[int64]*eddsa.PrivateKey)
+	model := &witnessTestModel{accounts: make(map[int64]*account.Account)}
+	for index := int64(1); index <= 3; index++ {
+		sk, err := eddsa.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		keys[index] = sk
+		model.accounts[index] = &account.Account{
+			AccountIndex:    index,
+			AccountName:     fmt.Sprintf("account%d.legend", index),
+			PublicKey:       common.Bytes2Hex(sk.PublicKey.Bytes()),
+			AccountNameHash: common.Bytes2Hex(common.LeftPadBytes(big.NewInt(index).Bytes(), 32)),
+			AssetInfo:       types.EmptyAccountAssetInfo,
+			AssetRoot:       common.Bytes2Hex(tree.NilAccountAssetRoot),
+		}
+	}
+	ctx, err := tree.NewContext("witness", tree.MemoryDB, false, 128, nil, nil, nil)
+	require.NoError(t, err)
+	accountTree, assetTrees, err := tree.InitAccountTree(model, model, 0, ctx, 16)
+	require.NoError(t, err)
+	nftTree, err := tree.InitNftTree(model, 0, ctx)
+	require.NoError(t, err)
+	helper := NewWitnessHelper(ctx, accountTree, nftTree, assetTrees, model, model)
+
+	expiredAt := time.Now().Add(time.Hour).UnixMilli()
+	asset := func(balance int64) string {
+		return types.ConstructAccountAsset(0, big.NewInt(balance), types.ZeroBigInt).String()
+	}
+	detail := func(accountIndex, accountOrder, nonce int64, balance, delta int64) *tx.TxDetail {
+		return &tx.TxDetail{AssetType: types.FungibleAssetType, AccountIndex: accountIndex, AccountOrder: accountOrder,
+			Nonce: nonce, Balance: asset(balance), BalanceDelta: asset(delta)}
+	}
+	gasDetail := func(balance, delta int64) *tx.TxDetail {
+		d := detail(types.GasAccount, 3, 0, balance, delta)
+		d.IsGas = true
+		return d
+	}
+	nftDetail := func(before, after *types.NftInfo) *tx.TxDetail {
+		return &tx.TxDetail{AssetType: types.NftAssetType, AccountIndex: after.OwnerAccountIndex,
+			Balance: before.String(), BalanceDelta: after.String()}
+	}
+	toTxInfo := func(info interface{}, err error) string {
+		require.NoError(t, err)
+		bz, err := json.Marshal(info)
+		require.NoError(t, err)
+		return string(bz)
+	}
+
+	// account 2 transfers to account 3
+	transferInfo := toTxInfo(txtypes.ConstructTransferTxInfo(keys[2], fmt.Sprintf(
+		`{"from_account_index":2,"to_account_index":3,"to_account_name":"%s","asset_id":0,"asset_amount":"100",`+
+			`"gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":%d,"nonce":0}`,
+		model.accounts[3].AccountNameHash, expiredAt)))
+	// account 2 mints nft 0 to account 3
+	mintInfo, err := txtypes.ConstructMintNftTxInfo(keys[2], fmt.Sprintf(
+		`{"creator_account_index":2,"to_account_index":3,"to_account_name_hash":"%s","nft_content_hash":"%s",`+
+			`"nft_collection_id":0,"creator_treasury_rate":0,"gas_account_index":1,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"10","expired_at":%d,"nonce":1}`,
+		model.accounts[3].AccountNameHash, common.Bytes2Hex(make([]byte, 32)), expiredAt))
+	require.NoError(t, err)
+	mintInfo.NftIndex = 0
+	mintedNft := types.ConstructNftInfo(0, 2, 3, mintInfo.NftContentHash, "0", "0", 0, 0)
+	// account 2 buys nft 0 from account 3
+	offer := func(offerType, accountIndex int64) string {
+		return toTxInfo(txtypes.ConstructOfferTxInfo(keys[accountIndex], fmt.Sprintf(
+			`{"type":%d,"offer_id":0,"account_index":%d,"nft_index":0,"asset_id":0,"asset_amount":"100",`+
+				`"listed_at":%d,"expired_at":%d,"treasury_rate":200}`, offerType, accountIndex, expiredAt-1, expiredAt)))
+	}
+	matchInfo, err := txtypes.ConstructAtomicMatchTxInfo(keys[2], fmt.Sprintf(
+		`{"account_index":2,"buy_offer":%q,"sell_offer":%q,"gas_account_index":1,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"10","nonce":2,"expired_at":%d}`, offer(0, 2), offer(1, 3), expiredAt))
+	require.NoError(t, err)
+	matchInfo.CreatorAmount = big.NewInt(0)
+	matchInfo.TreasuryAmount = big.NewInt(2)
+	boughtNft := types.ConstructNftInfo(0, 2, 2, mintInfo.NftContentHash, "0", "0", 0, 0)
+
+	b := &block.Block{
+		BlockHeight: 1,
+		BlockSize:   4,
+		Txs: []*tx.Tx{{
+			TxType: types.TxTypeTransfer, AccountIndex: 2, Nonce: 0, ExpiredAt: expiredAt, TxInfo: transferInfo,
+			TxDetails: []*tx.TxDetail{
+				detail(2, 0, 0, 1000, -100),
+				detail(2, 0, 0, 900, -10),
+				detail(3, 1, 0, 0, 100),
+				gasDetail(0, 10),
+			},
+		}, {
+			TxType: types.TxTypeMintNft, AccountIndex: 2, Nonce: 1, ExpiredAt: expiredAt, TxInfo: toTxInfo(mintInfo, nil),
+			TxDetails: []*tx.TxDetail{
+				detail(2, 0, 1, 890, -10),
+				detail(3, 1, 0, 100, 0),
+				nftDetail(types.EmptyNftInfo(0), mintedNft),
+				gasDetail(10, 10),
+			},
+		}, {
+			TxType: types.TxTypeAtomicMatch, AccountIndex: 2, Nonce: 2, ExpiredAt: expiredAt, TxInfo: toTxInfo(matchInfo, nil),
+			TxDetails: []*tx.TxDetail{
+				detail(2, 0, 2, 880, -100),
+				detail(2, 0, 2, 780, -10),
+				detail(3, 1, 0, 100, 98),
+				detail(2, 2, 3, 770, 0),
+				nftDetail(mintedNft, boughtNft),
+				gasDetail(20, 12),
+			},
+		}},
+	}
+
+	require.NoError(t, helper.ResetCache(b.BlockHeight))
+	cBlock := &circuit.Block{
+		BlockNumber:     b.BlockHeight,
+		CreatedAt:       time.Now().UnixMilli(),
+		BlockCommitment: make([]byte, 32),
+	}
+	for _, oTx := range b.Txs {
+		txWitness, err := helper.ConstructTxWitness(oTx, 0)
+		require.NoError(t, err)
+		cBlock.Txs = append(cBlock.Txs, txWitness)
+	}
+	cBlock.OldStateRoot = cBlock.Txs[0].StateRootBefore
+	cBlock.NewStateRoot = cBlock.Txs[len(cBlock.Txs)-1].StateRootAfter
+	for len(cBlock.Txs) < int(b.BlockSize) {
+		cBlock.Txs = append(cBlock.Txs, circuit.EmptyTx(cBlock.NewStateRoot))
+	}
+	cBlock.Gas, err = helper.ConstructGasWitness(b)
+	require.NoError(t, err)
+	return cBlock
+}
+
+// marshalCircuitWitness returns the full circuit assignment of the block, two blocks
+// with the same assignment produce the same proof.
+func marshalCircuitWitness(t *testing.T, cBlock *circuit.Block) []byte {
+	blockWitness, err := circuit.SetBlockWitness(cBlock)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&blockWitness, ecc.BN254)
+	assert.NoError(t, err)
+	bz, err := witness.MarshalBinary()
+	assert.NoError(t, err)
+	return bz
+}
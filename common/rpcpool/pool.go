@@ -0,0 +1,279 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+)
+
+const (
+	defaultCheckInterval = 10
+	defaultMaxHeightLag  = 20
+	defaultMaxErrorRate  = 0.5
+	defaultQuorum        = 1
+
+	checkTimeout = 5 * time.Second
+	// The error rate of an endpoint is measured over its latest health checks.
+	checkWindow = 20
+)
+
+type Config struct {
+	// Seconds between two health checks of the endpoints.
+	//nolint:staticcheck
+	CheckInterval int `json:",optional"`
+	// An endpoint is unhealthy if its latest height lags behind the highest one by more blocks.
+	//nolint:staticcheck
+	MaxHeightLag uint64 `json:",optional"`
+	// An endpoint is unhealthy if more of its latest health checks fail.
+	//nolint:staticcheck
+	MaxErrorRate float64 `json:",optional"`
+	// The number of endpoints which have to agree on the result of a critical read.
+	//nolint:staticcheck
+	Quorum int `json:",optional"`
+}
+
+type endpoint struct {
+	url     string
+	cli     *rpc.ProviderClient
+	height  uint64
+	results []bool
+	healthy bool
+}
+
+func (e *endpoint) errorRate() float64 {
+	if len(e.results) == 0 {
+		return 0
+	}
+	failures := 0
+	for _, ok := range e.results {
+		if !ok {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(e.results))
+}
+
+// Pool holds the clients of a list of l1 rpc endpoints in the order of preference. The
+// endpoints are checked periodically, and Client returns the first healthy one, so that
+// it fails over to another endpoint when the preferred one falls behind or errors.
+type Pool struct {
+	config    Config
+	endpoints []*endpoint
+
+	lock    sync.RWMutex
+	current int
+
+	quitCh chan struct{}
+}
+
+// ParseEndpoints splits a comma separated list of endpoints.
+func ParseEndpoints(value string) []string {
+	var urls []string
+	for _, url := range strings.Split(value, ",") {
+		url = strings.TrimSpace(url)
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+func NewPool(urls []string, config Config) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no rpc endpoint")
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultCheckInterval
+	}
+	if config.MaxHeightLag == 0 {
+		config.MaxHeightLag = defaultMaxHeightLag
+	}
+	if config.MaxErrorRate <= 0 {
+		config.MaxErrorRate = defaultMaxErrorRate
+	}
+	if config.Quorum <= 0 {
+		config.Quorum = defaultQuorum
+	}
+	if config.Quorum > len(urls) {
+		return nil, fmt.Errorf("quorum %d is larger than the number of endpoints %d", config.Quorum, len(urls))
+	}
+
+	p := &Pool{
+		config: config,
+		quitCh: make(chan struct{}),
+	}
+	for _, url := range urls {
+		cli, err := rpc.NewClient(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial %s, err: %v", url, err)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{url: url, cli: cli, healthy: true})
+	}
+	if len(p.endpoints) > 1 {
+		p.check()
+		go p.loop()
+	}
+	return p, nil
+}
+
+// Client returns the client of the preferred healthy endpoint. The preferred endpoint can
+// change between two calls, use Do for calls which have to be consistent with each other.
+func (p *Pool) Client() *rpc.ProviderClient {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.endpoints[p.current].cli
+}
+
+// Do runs a unit of work against the client of the preferred healthy endpoint. All the calls
+// of fn go to the same endpoint even if the pool fails over meanwhile, so that fn does not
+// compare the results of endpoints at different heights.
+func (p *Pool) Do(fn func(cli *rpc.ProviderClient) error) error {
+	return fn(p.Client())
+}
+
+// QuorumRead runs read against the endpoints, healthy ones first, until a quorum of them
+// return the same result. The result has to be comparable.
+func (p *Pool) QuorumRead(read func(cli *rpc.ProviderClient) (interface{}, error)) (interface{}, error) {
+	p.lock.RLock()
+	var endpoints []*endpoint
+	for _, healthy := range []bool{true, false} {
+		for _, e := range p.endpoints {
+			if e.healthy == healthy {
+				endpoints = append(endpoints, e)
+			}
+		}
+	}
+	p.lock.RUnlock()
+
+	var (
+		votes   = make(map[interface{}]int)
+		lastErr error
+	)
+	for i, e := range endpoints {
+		result, err := read(e.cli)
+		if err != nil {
+			logx.Errorf("failed to read from rpc endpoint %s, err: %v", e.url, err)
+			lastErr = err
+			continue
+		}
+		votes[result]++
+		if votes[result] >= p.config.Quorum {
+			return result, nil
+		}
+		// Stop early once no result can reach the quorum.
+		maxVotes := 0
+		for _, count := range votes {
+			if count > maxVotes {
+				maxVotes = count
+			}
+		}
+		if maxVotes+len(endpoints)-i-1 < p.config.Quorum {
+			break
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("rpc endpoints do not reach quorum %d, last err: %v", p.config.Quorum, lastErr)
+	}
+	return nil, fmt.Errorf("rpc endpoints do not reach quorum %d, results: %v", p.config.Quorum, votes)
+}
+
+func (p *Pool) Stop() {
+	if len(p.endpoints) > 1 {
+		close(p.quitCh)
+	}
+}
+
+func (p *Pool) loop() {
+	ticker := time.NewTicker(time.Duration(p.config.CheckInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.check()
+		case <-p.quitCh:
+			return
+		}
+	}
+}
+
+func (p *Pool) check() {
+	var (
+		wg      sync.WaitGroup
+		heights = make([]uint64, len(p.endpoints))
+		errs    = make([]error, len(p.endpoints))
+	)
+	for i, e := range p.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			defer cancel()
+			heights[i], errs[i] = e.cli.BlockNumber(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var maxHeight uint64
+	for i, e := range p.endpoints {
+		e.results = append(e.results, errs[i] == nil)
+		if len(e.results) > checkWindow {
+			e.results = e.results[1:]
+		}
+		if errs[i] != nil {
+			logx.Errorf("rpc endpoint %s health check failed, err: %v", e.url, errs[i])
+			continue
+		}
+		e.height = heights[i]
+		if e.height > maxHeight {
+			maxHeight = e.height
+		}
+	}
+
+	current := -1
+	for i, e := range p.endpoints {
+		healthy := errs[i] == nil && e.height+p.config.MaxHeightLag >= maxHeight &&
+			e.errorRate() <= p.config.MaxErrorRate
+		if healthy != e.healthy {
+			logx.Infof("rpc endpoint %s becomes healthy: %v, height: %d, error rate: %.2f",
+				e.url, healthy, e.height, e.errorRate())
+		}
+		e.healthy = healthy
+		if healthy && current < 0 {
+			current = i
+		}
+	}
+	if current < 0 {
+		logx.Severe("no healthy rpc endpoint")
+		return
+	}
+	if current != p.current {
+		logx.Infof("fail over from rpc endpoint %s to %s", p.endpoints[p.current].url, p.endpoints[current].url)
+		p.current = current
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rpcpool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+)
+
+// newNode serves eth_blockNumber with the given height, or fails if the height is 0.
+func newNode(height *uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := atomic.LoadUint64(height)
+		if h == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req struct {
+			Id json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.Id, h)
+	}))
+}
+
+func TestPoolFailover(t *testing.T) {
+	heights := []uint64{100, 100, 50}
+	var urls []string
+	for i := range heights {
+		node := newNode(&heights[i])
+		defer node.Close()
+		urls = append(urls, node.URL)
+	}
+
+	pool, err := NewPool(urls, Config{CheckInterval: 3600, Quorum: 2})
+	assert.NoError(t, err)
+	defer pool.Stop()
+	assert.Equal(t, urls[0], pool.endpoints[pool.current].url)
+
+	// the preferred endpoint fails
+	atomic.StoreUint64(&heights[0], 0)
+	pool.check()
+	assert.Equal(t, urls[1], pool.endpoints[pool.current].url)
+	assert.False(t, pool.endpoints[2].healthy)
+
+	// the lagging endpoint catches up and the preferred one recovers
+	atomic.StoreUint64(&heights[0], 200)
+	atomic.StoreUint64(&heights[1], 0)
+	atomic.StoreUint64(&heights[2], 200)
+	pool.check()
+	// one failure out of three checks is tolerated
+	assert.Equal(t, urls[0], pool.endpoints[pool.current].url)
+	assert.True(t, pool.endpoints[2].healthy)
+	assert.False(t, pool.endpoints[1].healthy)
+
+	height, err := pool.QuorumRead(func(cli *rpc.ProviderClient) (interface{}, error) {
+		return cli.BlockNumber(context.Background())
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(200), height)
+
+	// the healthy endpoints disagree
+	atomic.StoreUint64(&heights[2], 300)
+	_, err = pool.QuorumRead(func(cli *rpc.ProviderClient) (interface{}, error) {
+		return cli.BlockNumber(context.Background())
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"http://a", "http://b"}, ParseEndpoints(" http://a, ,http://b "))
+	_, err = NewPool(urls[:1], Config{Quorum: 2})
+	assert.Error(t, err)
+}
+
+func TestPoolDo(t *testing.T) {
+	heights := []uint64{100, 150}
+	var urls []string
+	for i := range heights {
+		node := newNode(&heights[i])
+		defer node.Close()
+		urls = append(urls, node.URL)
+	}
+
+	pool, err := NewPool(urls, Config{CheckInterval: 3600, MaxHeightLag: 100})
+	assert.NoError(t, err)
+	defer pool.Stop()
+
+	err = pool.Do(func(cli *rpc.ProviderClient) error {
+		height, err := cli.BlockNumber(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), height)
+
+		// the pool fails over in the middle of the unit of work
+		atomic.StoreUint64(&heights[0], 1)
+		pool.check()
+		assert.Equal(t, urls[1], pool.endpoints[pool.current].url)
+
+		height, err = cli.BlockNumber(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), height)
+		return nil
+	})
+	assert.NoError(t, err)
+}
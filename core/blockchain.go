@@ -21,6 +21,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/account"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/dbcache"
 	"github.com/bnb-chain/zkbnb/dao/nft"
@@ -59,8 +60,17 @@ type ChainConfig struct {
 		//nolint:staticcheck
 		RedisDBOption tree.RedisDBOption `json:",optional"`
 		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+		//nolint:staticcheck
 		RoutinePoolSize    int `json:",optional"`
 		AssetTreeCacheSize int
+		// The number of recently active accounts whose asset trees are loaded when the committer
+		// starts, 0 disables the warm up.
+		//nolint:staticcheck
+		AssetTreeWarmUpSize int `json:",optional"`
+		// The number of blocks before the latest verified block whose tree versions are kept.
+		//nolint:staticcheck
+		RetainedBlocks int64 `json:",optional"`
 	}
 }
 
@@ -100,7 +110,7 @@ func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error)
 		curHeight--
 	}
 	redisCache := dbcache.NewRedisCache(config.CacheRedis[0].Host, config.CacheRedis[0].Pass, 15*time.Minute)
-	treeCtx, err := tree.NewContext(moduleName, config.TreeDB.Driver, false, config.TreeDB.RoutinePoolSize, &config.TreeDB.LevelDBOption, &config.TreeDB.RedisDBOption)
+	treeCtx, err := tree.NewContext(moduleName, config.TreeDB.Driver, false, config.TreeDB.RoutinePoolSize, &config.TreeDB.LevelDBOption, &config.TreeDB.RedisDBOption, &config.TreeDB.BadgerDBOption)
 	if err != nil {
 		return nil, err
 	}
@@ -169,6 +179,22 @@ func (bc *BlockChain) InitNewBlock() (*block.Block, error) {
 	return newBlock, err
 }
 
+// prunedVersion returns the tree version to prune to, the trees are not pruned further if the
+// latest verified height is unknown.
+func (bc *BlockChain) prunedVersion() uint64 {
+	if bc.chainConfig.TreeDB.RetainedBlocks < 0 {
+		return 0
+	}
+	latestVerifiedHeight, err := bc.BlockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		if err != types.DbErrNotFound {
+			logx.Errorf("get latest verified height failed, skip pruning trees: %v", err)
+		}
+		return 0
+	}
+	return tree.PrunedVersion(latestVerifiedHeight, bc.chainConfig.TreeDB.RetainedBlocks)
+}
+
 func (bc *BlockChain) CurrentBlock() *block.Block {
 	return bc.currentBlock
 }
@@ -182,7 +208,7 @@ func (bc *BlockChain) CommitNewBlock(blockSize int, createdAt int64) (*block.Blo
 	currentHeight := bc.currentBlock.BlockHeight
 
 	start := time.Now()
-	err = tree.CommitTrees(uint64(currentHeight), bc.Statedb.AccountTree, bc.Statedb.AccountAssetTrees, bc.Statedb.NftTree)
+	err = tree.CommitTrees(bc.prunedVersion(), bc.Statedb.AccountTree, bc.Statedb.AccountAssetTrees, bc.Statedb.NftTree)
 	if err != nil {
 		return nil, err
 	}
@@ -198,13 +224,20 @@ func (bc *BlockChain) CommitNewBlock(blockSize int, createdAt int64) (*block.Blo
 		return nil, err
 	}
 
+	pendingCollection, pendingCollectionNftCount, err := collection.GetCollectionsOfTxs(newBlock.BlockHeight, newBlock.Txs)
+	if err != nil {
+		return nil, err
+	}
+
 	return &block.BlockStates{
-		Block:                 newBlock,
-		CompressedBlock:       compressedBlock,
-		PendingAccount:        pendingAccount,
-		PendingAccountHistory: pendingAccountHistory,
-		PendingNft:            pendingNft,
-		PendingNftHistory:     pendingNftHistory,
+		Block:                     newBlock,
+		CompressedBlock:           compressedBlock,
+		PendingAccount:            pendingAccount,
+		PendingAccountHistory:     pendingAccountHistory,
+		PendingNft:                pendingNft,
+		PendingNftHistory:         pendingNftHistory,
+		PendingCollection:         pendingCollection,
+		PendingCollectionNftCount: pendingCollectionNftCount,
 	}, nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/account"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/nft"
 	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
@@ -28,6 +29,7 @@ type ChainDB struct {
 	L2AssetInfoModel    asset.AssetModel
 	L2NftModel          nft.L2NftModel
 	L2NftHistoryModel   nft.L2NftHistoryModel
+	CollectionModel     collection.CollectionModel
 	TxPoolModel         tx.TxPoolModel
 
 	// Sys config
@@ -47,6 +49,7 @@ func NewChainDB(db *gorm.DB) *ChainDB {
 		L2AssetInfoModel:    asset.NewAssetModel(db),
 		L2NftModel:          nft.NewL2NftModel(db),
 		L2NftHistoryModel:   nft.NewL2NftHistoryModel(db),
+		CollectionModel:     collection.NewCollectionModel(db),
 		TxPoolModel:         tx.NewTxPoolModel(db),
 
 		SysConfigModel: sysconfig.NewSysConfigModel(db),
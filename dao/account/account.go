@@ -18,6 +18,8 @@
 package account
 
 import (
+	"strings"
+
 	"gorm.io/gorm"
 
 	"github.com/bnb-chain/zkbnb/types"
@@ -41,9 +43,12 @@ type (
 		GetAccountByPk(pk string) (account *Account, err error)
 		GetAccountByName(name string) (account *Account, err error)
 		GetAccountByNameHash(nameHash string) (account *Account, err error)
+		GetAccountsByL1Address(l1Address string, limit int, offset int64) (accounts []*Account, err error)
+		GetAccountsCountByL1Address(l1Address string) (count int64, err error)
 		GetAccounts(limit int, offset int64) (accounts []*Account, err error)
 		GetAccountsTotalCount() (count int64, err error)
 		UpdateAccountsInTransact(tx *gorm.DB, accounts []*Account) error
+		CreateAccountsInTransact(tx *gorm.DB, accounts []*Account) error
 	}
 
 	defaultAccountModel struct {
@@ -60,7 +65,7 @@ type (
 		AccountName     string `gorm:"uniqueIndex"`
 		PublicKey       string `gorm:"uniqueIndex"`
 		AccountNameHash string `gorm:"uniqueIndex"`
-		L1Address       string
+		L1Address       string `gorm:"index:idx_account_lower_l1_address,expression:lower(l1_address)"`
 		Nonce           int64
 		CollectionNonce int64
 		// map[int64]*AccountAsset
@@ -130,6 +135,28 @@ func (m *defaultAccountModel) GetAccountByNameHash(accountNameHash string) (acco
 	return account, nil
 }
 
+// GetAccountsByL1Address returns the accounts registered by the l1 address, ignoring the
+// case of its hex letters.
+func (m *defaultAccountModel) GetAccountsByL1Address(l1Address string, limit int, offset int64) (accounts []*Account, err error) {
+	dbTx := m.DB.Table(m.table).Where("lower(l1_address) = ?", strings.ToLower(l1Address)).
+		Limit(limit).Offset(int(offset)).Order("account_index").Find(&accounts)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return accounts, nil
+}
+
+func (m *defaultAccountModel) GetAccountsCountByL1Address(l1Address string) (count int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("lower(l1_address) = ? AND deleted_at is NULL", strings.ToLower(l1Address)).
+		Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return count, nil
+}
+
 func (m *defaultAccountModel) GetAccounts(limit int, offset int64) (accounts []*Account, err error) {
 	dbTx := m.DB.Table(m.table).Limit(limit).Offset(int(offset)).Order("account_index desc").Find(&accounts)
 	if dbTx.Error != nil {
@@ -178,3 +205,14 @@ func (m *defaultAccountModel) UpdateAccountsInTransact(tx *gorm.DB, accounts []*
 	}
 	return nil
 }
+
+func (m *defaultAccountModel) CreateAccountsInTransact(tx *gorm.DB, accounts []*Account) error {
+	dbTx := tx.Table(m.table).CreateInBatches(accounts, len(accounts))
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	if dbTx.RowsAffected != int64(len(accounts)) {
+		return types.DbErrFailToCreateAccount
+	}
+	return nil
+}
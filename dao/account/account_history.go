@@ -24,6 +24,9 @@ import (
 
 const (
 	AccountHistoryTableName = `account_history`
+
+	// The number of histories scanned for each recent active account to return.
+	recentActiveHistoryScanFactor = 4
 )
 
 type (
@@ -34,6 +37,9 @@ type (
 		GetValidAccountCount(height int64) (accounts int64, err error)
 		CreateAccountHistoriesInTransact(tx *gorm.DB, histories []*AccountHistory) error
 		GetLatestAccountHistory(accountIndex, height int64) (accountHistory *AccountHistory, err error)
+		GetRecentActiveAccountIndexes(height int64, limit int) (accountIndexes []int64, err error)
+		GetAccountHistoriesByHeightRange(fromHeight, toHeight int64) (accountHistories []*AccountHistory, err error)
+		GetAccountIndexesChangedAfter(height int64) (accountIndexes []int64, err error)
 	}
 
 	defaultAccountHistoryModel struct {
@@ -131,3 +137,48 @@ func (m *defaultAccountHistoryModel) GetLatestAccountHistory(accountIndex, heigh
 	}
 	return accountHistory, nil
 }
+
+// GetRecentActiveAccountIndexes returns the indexes of up to limit accounts changed most recently
+// up to the height, the most recent one first. Only the latest histories are scanned, so fewer
+// accounts may be returned when a few accounts are changed over and over.
+func (m *defaultAccountHistoryModel) GetRecentActiveAccountIndexes(height int64, limit int) (accountIndexes []int64, err error) {
+	var histories []*AccountHistory
+	dbTx := m.DB.Table(m.table).Select("account_index").
+		Where("l2_block_height <= ? AND l2_block_height != -1", height).
+		Order("id desc").Limit(limit * recentActiveHistoryScanFactor).Find(&histories)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	seen := make(map[int64]bool, limit)
+	for _, history := range histories {
+		if seen[history.AccountIndex] {
+			continue
+		}
+		seen[history.AccountIndex] = true
+		accountIndexes = append(accountIndexes, history.AccountIndex)
+		if len(accountIndexes) == limit {
+			break
+		}
+	}
+	return accountIndexes, nil
+}
+
+// GetAccountHistoriesByHeightRange returns the histories of the blocks after fromHeight up to
+// toHeight, in the order they are written.
+func (m *defaultAccountHistoryModel) GetAccountHistoriesByHeightRange(fromHeight, toHeight int64) (accountHistories []*AccountHistory, err error) {
+	dbTx := m.DB.Table(m.table).Where("l2_block_height > ? AND l2_block_height <= ?", fromHeight, toHeight).
+		Order("l2_block_height, id").Find(&accountHistories)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return accountHistories, nil
+}
+
+func (m *defaultAccountHistoryModel) GetAccountIndexesChangedAfter(height int64) (accountIndexes []int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("l2_block_height > ?", height).
+		Distinct("account_index").Order("account_index").Pluck("account_index", &accountIndexes)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return accountIndexes, nil
+}
@@ -23,6 +23,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/collection"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/nft"
 	"github.com/bnb-chain/zkbnb/dao/tx"
@@ -60,6 +61,7 @@ type (
 		CreateBlockInTransact(tx *gorm.DB, oBlock *Block) error
 		UpdateBlocksWithoutTxsInTransact(tx *gorm.DB, blocks []*Block) (err error)
 		UpdateBlockInTransact(tx *gorm.DB, block *Block) (err error)
+		GetBlocksByL1TxHashes(txHashes []string) (blocks []*Block, err error)
 	}
 
 	defaultBlockModel struct {
@@ -93,6 +95,9 @@ type (
 		PendingAccountHistory []*account.AccountHistory
 		PendingNft            []*nft.L2Nft
 		PendingNftHistory     []*nft.L2NftHistory
+
+		PendingCollection         []*collection.Collection
+		PendingCollectionNftCount map[collection.Key]int64
 	}
 )
 
@@ -311,7 +316,7 @@ func (m *defaultBlockModel) GetLatestVerifiedHeight() (height int64, err error)
 	dbTx := m.DB.Table(m.table).Where("block_status = ?", StatusVerifiedAndExecuted).
 		Order("block_height DESC").
 		Limit(1).
-		First(&block)
+		Find(&block)
 	if dbTx.Error != nil {
 		return 0, types.DbErrSqlOperation
 	} else if dbTx.RowsAffected == 0 {
@@ -371,3 +376,15 @@ func (m *defaultBlockModel) UpdateBlockInTransact(tx *gorm.DB, block *Block) (er
 	}
 	return nil
 }
+
+func (m *defaultBlockModel) GetBlocksByL1TxHashes(txHashes []string) (blocks []*Block, err error) {
+	dbTx := m.DB.Table(m.table).Where("committed_tx_hash IN ? OR verified_tx_hash IN ?", txHashes, txHashes).
+		Order("block_height").Find(&blocks)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return blocks, nil
+}
@@ -41,7 +41,9 @@ type (
 		DropBlockWitnessTable() error
 		GetLatestBlockWitnessHeight() (height int64, err error)
 		GetBlockWitnessByHeight(height int64) (witness *BlockWitness, err error)
+		GetJsonBlockWitnesses(fromHeight int64, limit int) (witnesses []*BlockWitness, err error)
 		UpdateBlockWitnessStatus(witness *BlockWitness, status int64) error
+		UpdateBlockWitnessData(witness *BlockWitness) error
 		GetLatestBlockWitness() (witness *BlockWitness, err error)
 		CreateBlockWitness(witness *BlockWitness) error
 	}
@@ -53,9 +55,12 @@ type (
 
 	BlockWitness struct {
 		gorm.Model
-		Height      int64 `gorm:"index:idx_height,unique"`
+		Height int64 `gorm:"index:idx_height,unique"`
+		// WitnessData is the legacy json encoded witness, it is empty when WitnessBinary is set.
 		WitnessData string
-		Status      int64
+		// WitnessBinary is the versioned binary encoded witness.
+		WitnessBinary []byte
+		Status        int64
 	}
 )
 
@@ -109,6 +114,17 @@ func (m *defaultBlockWitnessModel) GetBlockWitnessByHeight(height int64) (witnes
 	return witness, nil
 }
 
+func (m *defaultBlockWitnessModel) GetJsonBlockWitnesses(fromHeight int64, limit int) (witnesses []*BlockWitness, err error) {
+	dbTx := m.DB.Table(m.table).Where("height >= ? AND witness_data <> ''", fromHeight).
+		Order("height asc").Limit(limit).Find(&witnesses)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return witnesses, nil
+}
+
 func (m *defaultBlockWitnessModel) CreateBlockWitness(witness *BlockWitness) error {
 	if witness.Height > 1 {
 		_, err := m.GetBlockWitnessByHeight(witness.Height - 1)
@@ -133,3 +149,19 @@ func (m *defaultBlockWitnessModel) UpdateBlockWitnessStatus(witness *BlockWitnes
 	}
 	return nil
 }
+
+func (m *defaultBlockWitnessModel) UpdateBlockWitnessData(witness *BlockWitness) error {
+	dbTx := m.DB.Table(m.table).Where("id = ?", witness.ID).
+		Select("witness_data", "witness_binary").
+		Updates(map[string]interface{}{
+			"witness_data":   witness.WitnessData,
+			"witness_binary": witness.WitnessBinary,
+		})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return types.DbErrFailToUpdateBlockWitness
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package collection
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	CollectionTableName = `collection`
+)
+
+type (
+	CollectionModel interface {
+		CreateCollectionTable() error
+		DropCollectionTable() error
+		GetCollection(creatorAccountIndex, collectionId int64) (collection *Collection, err error)
+		GetCollections(limit int, offset int64) (collections []*Collection, err error)
+		GetCollectionsTotalCount() (count int64, err error)
+		CreateCollectionsInTransact(tx *gorm.DB, collections []*Collection) error
+		DeleteAllCollectionsInTransact(tx *gorm.DB) error
+		UpdateNftCountsInTransact(tx *gorm.DB, nftCountDeltas map[Key]int64) error
+	}
+
+	defaultCollectionModel struct {
+		table string
+		DB    *gorm.DB
+	}
+
+	// Collection is created by a CreateCollection tx, its id is only unique among the
+	// collections of its creator.
+	Collection struct {
+		gorm.Model
+		CreatorAccountIndex int64 `gorm:"uniqueIndex:idx_creator_collection"`
+		CollectionId        int64 `gorm:"uniqueIndex:idx_creator_collection"`
+		Name                string
+		Introduction        string
+		// The number of nfts of the collection in l2, the nfts withdrawn to l1 are not counted,
+		// the same as the nfts counted by L2NftModel.GetNftsCountByCollection.
+		NftCount      int64
+		L2BlockHeight int64
+	}
+
+	Key struct {
+		CreatorAccountIndex int64
+		CollectionId        int64
+	}
+)
+
+func NewCollectionModel(db *gorm.DB) CollectionModel {
+	return &defaultCollectionModel{
+		table: CollectionTableName,
+		DB:    db,
+	}
+}
+
+func (*Collection) TableName() string {
+	return CollectionTableName
+}
+
+func (m *defaultCollectionModel) CreateCollectionTable() error {
+	return m.DB.AutoMigrate(Collection{})
+}
+
+func (m *defaultCollectionModel) DropCollectionTable() error {
+	return m.DB.Migrator().DropTable(m.table)
+}
+
+func (m *defaultCollectionModel) GetCollection(creatorAccountIndex, collectionId int64) (collection *Collection, err error) {
+	dbTx := m.DB.Table(m.table).Where("creator_account_index = ? and collection_id = ?", creatorAccountIndex, collectionId).
+		Find(&collection)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return collection, nil
+}
+
+func (m *defaultCollectionModel) GetCollections(limit int, offset int64) (collections []*Collection, err error) {
+	dbTx := m.DB.Table(m.table).Limit(limit).Offset(int(offset)).Order("id desc").Find(&collections)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return collections, nil
+}
+
+func (m *defaultCollectionModel) GetCollectionsTotalCount() (count int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("deleted_at is NULL").Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return 0, nil
+	}
+	return count, nil
+}
+
+func (m *defaultCollectionModel) CreateCollectionsInTransact(tx *gorm.DB, collections []*Collection) error {
+	dbTx := tx.Table(m.table).CreateInBatches(collections, len(collections))
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	if dbTx.RowsAffected != int64(len(collections)) {
+		return types.DbErrFailToCreateCollection
+	}
+	return nil
+}
+
+func (m *defaultCollectionModel) DeleteAllCollectionsInTransact(tx *gorm.DB) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("1 = 1").Delete(&Collection{})
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	return nil
+}
+
+func (m *defaultCollectionModel) UpdateNftCountsInTransact(tx *gorm.DB, nftCountDeltas map[Key]int64) error {
+	// Update in a fixed order to avoid deadlocks between transactions.
+	keys := make([]Key, 0, len(nftCountDeltas))
+	for key := range nftCountDeltas {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatorAccountIndex != keys[j].CreatorAccountIndex {
+			return keys[i].CreatorAccountIndex < keys[j].CreatorAccountIndex
+		}
+		return keys[i].CollectionId < keys[j].CollectionId
+	})
+	for _, key := range keys {
+		dbTx := tx.Table(m.table).
+			Where("creator_account_index = ? and collection_id = ?", key.CreatorAccountIndex, key.CollectionId).
+			Update("nft_count", gorm.Expr("nft_count + ?", nftCountDeltas[key]))
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+	}
+	return nil
+}
+
+// GetCollectionsOfTxs returns the collections created by the txs, and the changes of the numbers
+// of the nfts in the collections made by the txs. The nfts are added to l2 by MintNft and
+// DepositNft txs, and removed by WithdrawNft and FullExitNft txs. The txs are executed in the
+// block of blockHeight, or in their own blocks if blockHeight is -1.
+func GetCollectionsOfTxs(blockHeight int64, txs []*tx.Tx) ([]*Collection, map[Key]int64, error) {
+	collections := make([]*Collection, 0)
+	nftCountDeltas := make(map[Key]int64)
+	for _, executedTx := range txs {
+		switch executedTx.TxType {
+		case types.TxTypeCreateCollection:
+			txInfo, err := types.ParseCreateCollectionTxInfo(executedTx.TxInfo)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse create collection tx failed: %v", err)
+			}
+			height := blockHeight
+			if height == -1 {
+				height = executedTx.BlockHeight
+			}
+			collections = append(collections, &Collection{
+				CreatorAccountIndex: txInfo.AccountIndex,
+				CollectionId:        txInfo.CollectionId,
+				Name:                txInfo.Name,
+				Introduction:        txInfo.Introduction,
+				L2BlockHeight:       height,
+			})
+		case types.TxTypeMintNft:
+			txInfo, err := types.ParseMintNftTxInfo(executedTx.TxInfo)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse mint nft tx failed: %v", err)
+			}
+			nftCountDeltas[Key{CreatorAccountIndex: txInfo.CreatorAccountIndex, CollectionId: txInfo.NftCollectionId}]++
+		case types.TxTypeDepositNft:
+			txInfo, err := types.ParseDepositNftTxInfo(executedTx.TxInfo)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse deposit nft tx failed: %v", err)
+			}
+			nftCountDeltas[Key{CreatorAccountIndex: txInfo.CreatorAccountIndex, CollectionId: txInfo.CollectionId}]++
+		case types.TxTypeWithdrawNft:
+			txInfo, err := types.ParseWithdrawNftTxInfo(executedTx.TxInfo)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse withdraw nft tx failed: %v", err)
+			}
+			nftCountDeltas[Key{CreatorAccountIndex: txInfo.CreatorAccountIndex, CollectionId: txInfo.CollectionId}]--
+		case types.TxTypeFullExitNft:
+			txInfo, err := types.ParseFullExitNftTxInfo(executedTx.TxInfo)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse full exit nft tx failed: %v", err)
+			}
+			// the nft is not owned by the account, nothing is exited
+			if len(bytes.TrimLeft(txInfo.NftContentHash, "\x00")) == 0 {
+				continue
+			}
+			nftCountDeltas[Key{CreatorAccountIndex: txInfo.CreatorAccountIndex, CollectionId: txInfo.CollectionId}]--
+		}
+	}
+	for key, delta := range nftCountDeltas {
+		if delta == 0 {
+			delete(nftCountDeltas, key)
+		}
+	}
+	return collections, nftCountDeltas, nil
+}
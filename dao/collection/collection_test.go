@@ -0,0 +1,64 @@
+package collection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestGetCollectionsOfTxs(t *testing.T) {
+	newTx := func(txType int, txInfo interface{}) *tx.Tx {
+		bz, err := json.Marshal(txInfo)
+		require.NoError(t, err)
+		return &tx.Tx{TxType: int64(txType), TxInfo: string(bz), BlockHeight: 8}
+	}
+	txs := []*tx.Tx{
+		newTx(types.TxTypeCreateCollection, &txtypes.CreateCollectionTxInfo{AccountIndex: 2, CollectionId: 1, Name: "art"}),
+		newTx(types.TxTypeMintNft, &txtypes.MintNftTxInfo{CreatorAccountIndex: 2, NftCollectionId: 1}),
+		newTx(types.TxTypeMintNft, &txtypes.MintNftTxInfo{CreatorAccountIndex: 2, NftCollectionId: 1}),
+		newTx(types.TxTypeMintNft, &txtypes.MintNftTxInfo{CreatorAccountIndex: 3, NftCollectionId: 0}),
+		newTx(types.TxTypeTransfer, &txtypes.TransferTxInfo{FromAccountIndex: 2}),
+		// an nft of collection 1 is withdrawn, and the other exits fully
+		newTx(types.TxTypeWithdrawNft, &txtypes.WithdrawNftTxInfo{CreatorAccountIndex: 2, CollectionId: 1}),
+		newTx(types.TxTypeFullExitNft, &txtypes.FullExitNftTxInfo{CreatorAccountIndex: 2, CollectionId: 1,
+			NftContentHash: []byte{1}}),
+		// an nft is deposited back to collection 1
+		newTx(types.TxTypeDepositNft, &txtypes.DepositNftTxInfo{CreatorAccountIndex: 2, CollectionId: 1,
+			NftContentHash: []byte{1}}),
+		// the full exit of an nft not owned by the account exits nothing
+		newTx(types.TxTypeFullExitNft, &txtypes.FullExitNftTxInfo{NftContentHash: []byte{0}}),
+		// the nft of account 3 is withdrawn
+		newTx(types.TxTypeWithdrawNft, &txtypes.WithdrawNftTxInfo{CreatorAccountIndex: 3, CollectionId: 0}),
+	}
+
+	collections, nftCounts, err := GetCollectionsOfTxs(10, txs)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, int64(2), collections[0].CreatorAccountIndex)
+	assert.Equal(t, int64(1), collections[0].CollectionId)
+	assert.Equal(t, "art", collections[0].Name)
+	assert.Equal(t, int64(10), collections[0].L2BlockHeight)
+	// the unchanged count of account 3 is dropped
+	assert.Equal(t, map[Key]int64{
+		{CreatorAccountIndex: 2, CollectionId: 1}: 1,
+	}, nftCounts)
+
+	_, nftCounts, err = GetCollectionsOfTxs(10, txs[5:])
+	require.NoError(t, err)
+	assert.Equal(t, map[Key]int64{
+		{CreatorAccountIndex: 2, CollectionId: 1}: -1,
+		{CreatorAccountIndex: 3, CollectionId: 0}: -1,
+	}, nftCounts)
+
+	// the txs are replayed from their own blocks
+	collections, _, err = GetCollectionsOfTxs(-1, txs)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, int64(8), collections[0].L2BlockHeight)
+}
@@ -69,3 +69,8 @@ func (c *RedisCache) Delete(ctx context.Context, key string) error {
 func (c *RedisCache) Close() error {
 	return c.clint.Close()
 }
+
+// IsKeyNotExist reports whether err is returned for a key which is not cached or expired.
+func IsKeyNotExist(err error) bool {
+	return err != nil && err.Error() == redisKeyNotExist.Error()
+}
@@ -28,8 +28,11 @@ import (
 const (
 	TableName = "l1_rollup_tx"
 
-	StatusPending = 1
-	StatusHandled = 2
+	StatusPending  = 1
+	StatusHandled  = 2
+	StatusReplaced = 3
+	// StatusFailed marks a tx reverted on l1, its blocks are sent again by a new tx
+	StatusFailed = 4
 
 	TxTypeCommit           = 1
 	TxTypeVerifyAndExecute = 2
@@ -46,6 +49,7 @@ type (
 		GetL1RollupTxsByHash(hash string) (txs []*L1RollupTx, err error)
 		DeleteL1RollupTx(tx *L1RollupTx) error
 		UpdateL1RollupTxsInTransact(tx *gorm.DB, txs []*L1RollupTx) error
+		GetHandledTxsForL1HeightGreaterThan(l1Height int64, l1TxHashes []string) (txs []*L1RollupTx, err error)
 	}
 
 	defaultL1RollupTxModel struct {
@@ -57,12 +61,18 @@ type (
 		gorm.Model
 		// txVerification hash
 		L1TxHash string
-		// txVerification status, 1 - pending, 2 - handled
+		// txVerification status, 1 - pending, 2 - handled, 3 - replaced by another tx with the same nonce
 		TxStatus int
 		// txVerification type: commit / verify
 		TxType uint8
 		// layer-2 block height
 		L2BlockHeight int64
+		// nonce of the sender account, txs with the same type and nonce replace each other
+		Nonce uint64
+		// gas price in wei
+		GasPrice string
+		// l1 block height the handled tx is mined in, 0 for txs handled by former versions
+		L1BlockHeight int64
 	}
 )
 
@@ -166,3 +176,21 @@ func (m *defaultL1RollupTxModel) UpdateL1RollupTxsInTransact(tx *gorm.DB, txs []
 	}
 	return nil
 }
+
+// GetHandledTxsForL1HeightGreaterThan returns the handled txs mined after the l1 height, txs
+// handled by former versions do not record the height and are matched by hash instead.
+func (m *defaultL1RollupTxModel) GetHandledTxsForL1HeightGreaterThan(l1Height int64, l1TxHashes []string) (txs []*L1RollupTx, err error) {
+	dbTx := m.DB.Table(m.table).Where("tx_status = ?", StatusHandled)
+	if len(l1TxHashes) == 0 {
+		dbTx = dbTx.Where("l1_block_height > ?", l1Height)
+	} else {
+		dbTx = dbTx.Where("l1_block_height > ? OR l1_tx_hash IN ?", l1Height, l1TxHashes)
+	}
+	dbTx = dbTx.Order("l2_block_height, tx_type").Find(&txs)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txs, nil
+}
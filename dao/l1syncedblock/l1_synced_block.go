@@ -37,6 +37,8 @@ type (
 		GetLatestL1SyncedBlockByType(blockType int) (blockInfo *L1SyncedBlock, err error)
 		DeleteL1SyncedBlocksForHeightLessThan(height int64) (err error)
 		CreateL1SyncedBlockInTransact(tx *gorm.DB, block *L1SyncedBlock) error
+		GetL1SyncedBlocksByType(blockType int, limit int) (blocks []*L1SyncedBlock, err error)
+		DeleteL1SyncedBlocksForHeightGreaterThanInTransact(tx *gorm.DB, blockType int, height int64) error
 	}
 
 	defaultL1EventModel struct {
@@ -48,6 +50,8 @@ type (
 		gorm.Model
 		// l1 block height
 		L1BlockHeight int64 `gorm:"index"`
+		// hash of the l1 block, to detect reorgs
+		L1BlockHash string
 		// block info, array of hashes
 		BlockInfo string
 		Type      int `gorm:"index"`
@@ -102,3 +106,22 @@ func (m *defaultL1EventModel) CreateL1SyncedBlockInTransact(tx *gorm.DB, block *
 	}
 	return nil
 }
+
+func (m *defaultL1EventModel) GetL1SyncedBlocksByType(blockType int, limit int) (blocks []*L1SyncedBlock, err error) {
+	dbTx := m.DB.Table(m.table).Where("type = ?", blockType).Order("l1_block_height desc").Limit(limit).Find(&blocks)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return blocks, nil
+}
+
+func (m *defaultL1EventModel) DeleteL1SyncedBlocksForHeightGreaterThanInTransact(tx *gorm.DB, blockType int, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("type = ? AND l1_block_height > ?", blockType, height).Delete(&L1SyncedBlock{})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	return nil
+}
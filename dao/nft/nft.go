@@ -35,7 +35,11 @@ type (
 		GetLatestNftIndex() (nftIndex int64, err error)
 		GetNftsByAccountIndex(accountIndex, limit, offset int64) (nfts []*L2Nft, err error)
 		GetNftsCountByAccountIndex(accountIndex int64) (int64, error)
+		GetNftsByCollection(creatorAccountIndex, collectionId, limit, offset int64) (nfts []*L2Nft, err error)
+		GetNftsCountByCollection(creatorAccountIndex, collectionId int64) (int64, error)
+		GetNfts(limit int, offset int64) (nfts []*L2Nft, err error)
 		UpdateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error
+		CreateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error
 	}
 	defaultL2NftModel struct {
 		table string
@@ -45,13 +49,13 @@ type (
 	L2Nft struct {
 		gorm.Model
 		NftIndex            int64 `gorm:"uniqueIndex"`
-		CreatorAccountIndex int64
+		CreatorAccountIndex int64 `gorm:"index:idx_l2_nft_creator_collection"`
 		OwnerAccountIndex   int64
 		NftContentHash      string
 		NftL1Address        string
 		NftL1TokenId        string
 		CreatorTreasuryRate int64
-		CollectionId        int64
+		CollectionId        int64 `gorm:"index:idx_l2_nft_creator_collection"`
 	}
 )
 
@@ -117,6 +121,41 @@ func (m *defaultL2NftModel) GetNftsCountByAccountIndex(accountIndex int64) (int6
 	return count, nil
 }
 
+// GetNftsByCollection returns the nfts of a collection which are not withdrawn.
+func (m *defaultL2NftModel) GetNftsByCollection(creatorAccountIndex, collectionId, limit, offset int64) (nftList []*L2Nft, err error) {
+	dbTx := m.DB.Table(m.table).Where("creator_account_index = ? and collection_id = ? and nft_content_hash != ? and deleted_at is NULL",
+		creatorAccountIndex, collectionId, types.EmptyNftContentHash).
+		Limit(int(limit)).Offset(int(offset)).Order("nft_index desc").Find(&nftList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return nftList, nil
+}
+
+func (m *defaultL2NftModel) GetNftsCountByCollection(creatorAccountIndex, collectionId int64) (int64, error) {
+	var count int64
+	dbTx := m.DB.Table(m.table).Where("creator_account_index = ? and collection_id = ? and nft_content_hash != ? and deleted_at is NULL",
+		creatorAccountIndex, collectionId, types.EmptyNftContentHash).Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return 0, types.DbErrNotFound
+	}
+	return count, nil
+}
+
+func (m *defaultL2NftModel) GetNfts(limit int, offset int64) (nfts []*L2Nft, err error) {
+	dbTx := m.DB.Table(m.table).Limit(limit).Offset(int(offset)).Order("nft_index").Find(&nfts)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return nfts, nil
+}
+
 func (m *defaultL2NftModel) UpdateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error {
 	for _, pendingNft := range nfts {
 		dbTx := tx.Table(m.table).Where("nft_index = ?", pendingNft.NftIndex).
@@ -134,3 +173,14 @@ func (m *defaultL2NftModel) UpdateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) err
 	}
 	return nil
 }
+
+func (m *defaultL2NftModel) CreateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error {
+	dbTx := tx.Table(m.table).CreateInBatches(nfts, len(nfts))
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	if dbTx.RowsAffected != int64(len(nfts)) {
+		return types.DbErrFailToCreateNft
+	}
+	return nil
+}
@@ -36,6 +36,7 @@ type (
 			rowsAffected int64, nftAssets []*L2NftHistory, err error,
 		)
 		CreateNftHistoriesInTransact(tx *gorm.DB, histories []*L2NftHistory) error
+		GetNftHistoriesByHeightRange(fromHeight, toHeight int64) (nftHistories []*L2NftHistory, err error)
 	}
 	defaultL2NftHistoryModel struct {
 		table string
@@ -119,3 +120,14 @@ func (m *defaultL2NftHistoryModel) CreateNftHistoriesInTransact(tx *gorm.DB, his
 	}
 	return nil
 }
+
+// GetNftHistoriesByHeightRange returns the histories of the blocks after fromHeight up to
+// toHeight, in the order they are written.
+func (m *defaultL2NftHistoryModel) GetNftHistoriesByHeightRange(fromHeight, toHeight int64) (nftHistories []*L2NftHistory, err error) {
+	dbTx := m.DB.Table(m.table).Where("l2_block_height > ? AND l2_block_height <= ?", fromHeight, toHeight).
+		Order("l2_block_height, id").Find(&nftHistories)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return nftHistories, nil
+}
@@ -35,10 +35,19 @@ type (
 		CreatePriorityRequestTable() error
 		DropPriorityRequestTable() error
 		GetPriorityRequestsByStatus(status int) (txs []*PriorityRequest, err error)
+		GetPriorityRequestCountByStatus(status int) (count int64, err error)
 		GetLatestHandledRequestId() (requestId int64, err error)
 		UpdateHandledPriorityRequestsInTransact(tx *gorm.DB, requests []*PriorityRequest) (err error)
 		CreatePriorityRequestsInTransact(tx *gorm.DB, requests []*PriorityRequest) (err error)
 		GetPriorityRequestsByL2TxHash(txHash string) (tx *PriorityRequest, err error)
+		GetOldestUnexecutedPriorityRequest(executedHeight int64) (request *PriorityRequest, err error)
+		GetUnexecutedPriorityRequestCount(executedHeight int64) (count int64, err error)
+		GetPriorityRequestsByRequestIds(requestIds []int64) (requests []*PriorityRequest, err error)
+		DeletePendingPriorityRequestsForHeightGreaterThanInTransact(tx *gorm.DB, l1Height int64) error
+		GetHandledPriorityRequestsForHeightGreaterThan(l1Height int64) (requests []*PriorityRequest, err error)
+		GetPriorityRequestsByL1TxHash(txHash string) (requests []*PriorityRequest, err error)
+		GetPriorityRequestsCountBySender(senderAddress string) (count int64, err error)
+		GetPriorityRequestsBySender(senderAddress string, limit int64, offset int64) (requests []*PriorityRequest, err error)
 	}
 
 	defaultPriorityRequestModel struct {
@@ -49,11 +58,11 @@ type (
 	PriorityRequest struct {
 		gorm.Model
 		// related txVerification hash
-		L1TxHash string
+		L1TxHash string `gorm:"index"`
 		// related block height
 		L1BlockHeight int64
 		// sender
-		SenderAddress string
+		SenderAddress string `gorm:"index"`
 		// request id
 		RequestId int64
 		// tx type
@@ -109,6 +118,14 @@ func (m *defaultPriorityRequestModel) GetPriorityRequestsByStatus(status int) (t
 	return txs, nil
 }
 
+func (m *defaultPriorityRequestModel) GetPriorityRequestCountByStatus(status int) (count int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("status = ?", status).Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return count, nil
+}
+
 func (m *defaultPriorityRequestModel) GetLatestHandledRequestId() (requestId int64, err error) {
 	var event *PriorityRequest
 	dbTx := m.DB.Table(m.table).Where("status = ?", HandledStatus).Order("request_id desc").Find(&event)
@@ -161,3 +178,94 @@ func (m *defaultPriorityRequestModel) GetPriorityRequestsByL2TxHash(txHash strin
 
 	return tx, nil
 }
+
+// unexecutedPriorityRequests selects the requests whose l2 txs are not included in any block
+// executed on l1 yet, that is blocks up to executedHeight. These requests are still in the
+// priority queue of the l1 contract and are subject to their expiration blocks.
+func (m *defaultPriorityRequestModel) unexecutedPriorityRequests(executedHeight int64) *gorm.DB {
+	return m.DB.Table(m.table+" AS pr").
+		Joins("LEFT JOIN tx ON tx.tx_hash = pr.l2_tx_hash AND tx.deleted_at IS NULL").
+		Where("pr.deleted_at IS NULL AND (tx.id IS NULL OR tx.block_height > ?)", executedHeight)
+}
+
+func (m *defaultPriorityRequestModel) GetOldestUnexecutedPriorityRequest(executedHeight int64) (request *PriorityRequest, err error) {
+	dbTx := m.unexecutedPriorityRequests(executedHeight).Select("pr.*").
+		Order("pr.request_id").Limit(1).Find(&request)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return request, nil
+}
+
+func (m *defaultPriorityRequestModel) GetUnexecutedPriorityRequestCount(executedHeight int64) (count int64, err error) {
+	dbTx := m.unexecutedPriorityRequests(executedHeight).Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return count, nil
+}
+
+func (m *defaultPriorityRequestModel) GetPriorityRequestsByRequestIds(requestIds []int64) (requests []*PriorityRequest, err error) {
+	dbTx := m.DB.Table(m.table).Where("request_id IN ?", requestIds).Find(&requests)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return requests, nil
+}
+
+func (m *defaultPriorityRequestModel) DeletePendingPriorityRequestsForHeightGreaterThanInTransact(tx *gorm.DB, l1Height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("status = ? AND l1_block_height > ?", PendingStatus, l1Height).
+		Delete(&PriorityRequest{})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	return nil
+}
+
+func (m *defaultPriorityRequestModel) GetHandledPriorityRequestsForHeightGreaterThan(l1Height int64) (requests []*PriorityRequest, err error) {
+	dbTx := m.DB.Table(m.table).Where("status = ? AND l1_block_height > ?", HandledStatus, l1Height).
+		Order("request_id").Find(&requests)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return requests, nil
+}
+
+func (m *defaultPriorityRequestModel) GetPriorityRequestsByL1TxHash(txHash string) (requests []*PriorityRequest, err error) {
+	dbTx := m.DB.Table(m.table).Where("l1_tx_hash = ?", txHash).Order("request_id").Find(&requests)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return requests, nil
+}
+
+func (m *defaultPriorityRequestModel) GetPriorityRequestsCountBySender(senderAddress string) (count int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("sender_address = ?", senderAddress).Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return count, nil
+}
+
+func (m *defaultPriorityRequestModel) GetPriorityRequestsBySender(senderAddress string, limit int64, offset int64) (requests []*PriorityRequest, err error) {
+	dbTx := m.DB.Table(m.table).Where("sender_address = ?", senderAddress).
+		Limit(int(limit)).Offset(int(offset)).Order("request_id desc").Find(&requests)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return requests, nil
+}
@@ -32,6 +32,7 @@ type (
 		CreateSysConfigTable() error
 		DropSysConfigTable() error
 		GetSysConfigByName(name string) (info *SysConfig, err error)
+		GetSysConfigs() (configs []*SysConfig, err error)
 		CreateSysConfigs(configs []*SysConfig) (rowsAffected int64, err error)
 		CreateSysConfigsInTransact(tx *gorm.DB, configs []*SysConfig) error
 		UpdateSysConfigsInTransact(tx *gorm.DB, configs []*SysConfig) error
@@ -80,6 +81,16 @@ func (m *defaultSysConfigModel) GetSysConfigByName(name string) (config *SysConf
 	return config, nil
 }
 
+func (m *defaultSysConfigModel) GetSysConfigs() (configs []*SysConfig, err error) {
+	dbTx := m.DB.Table(m.table).Order("id").Find(&configs)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return configs, nil
+}
+
 func (m *defaultSysConfigModel) CreateSysConfigs(configs []*SysConfig) (rowsAffected int64, err error) {
 	dbTx := m.DB.Table(m.table).CreateInBatches(configs, len(configs))
 	if dbTx.Error != nil {
@@ -80,6 +80,7 @@ type (
 		GetTxsByAccountIndex(accountIndex int64, limit int64, offset int64, options ...GetTxOptionFunc) (txList []*Tx, err error)
 		GetTxsCountByAccountIndex(accountIndex int64, options ...GetTxOptionFunc) (count int64, err error)
 		GetTxByHash(txHash string) (tx *Tx, err error)
+		GetTxsByTypesUpToHeight(txTypes []int64, height int64, fromId uint, limit int) (txList []*Tx, err error)
 		GetTxsTotalCountBetween(from, to time.Time) (count int64, err error)
 		GetDistinctAccountsCountBetween(from, to time.Time) (count int64, err error)
 		UpdateTxsStatusInTransact(tx *gorm.DB, blockTxStatus map[int64]int) error
@@ -231,6 +232,19 @@ func (m *defaultTxModel) GetTxByHash(txHash string) (tx *Tx, err error) {
 	return tx, nil
 }
 
+// GetTxsByTypesUpToHeight returns the txs of the types in the blocks up to the height, whose ids
+// are not less than fromId, in the order of their ids.
+func (m *defaultTxModel) GetTxsByTypesUpToHeight(txTypes []int64, height int64, fromId uint, limit int) (txList []*Tx, err error) {
+	dbTx := m.DB.Table(m.table).Where("tx_type IN ? AND block_height <= ? AND id >= ?", txTypes, height, fromId).
+		Order("id").Limit(limit).Find(&txList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txList, nil
+}
+
 func (m *defaultTxModel) GetTxsTotalCountBetween(from, to time.Time) (count int64, err error) {
 	dbTx := m.DB.Table(m.table).Where("created_at BETWEEN ? AND ?", from, to).Count(&count)
 	if dbTx.Error != nil {
@@ -0,0 +1,174 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package withdrawal
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	TableName = "withdrawal"
+)
+
+const (
+	_ = iota
+	// StatusWithdrawn means the assets are sent to the l1 address when the block is executed.
+	StatusWithdrawn
+	// StatusPendingBalance means the assets failed to be sent and are kept as pending balance
+	// in the contract, which is claimed by the owner later.
+	StatusPendingBalance
+	StatusClaimed
+)
+
+type (
+	WithdrawalModel interface {
+		CreateWithdrawalTable() error
+		DropWithdrawalTable() error
+		GetWithdrawalsByL2TxHashes(txHashes []string) (withdrawals []*Withdrawal, err error)
+		GetPendingBalanceWithdrawalsByAddress(toAddress string) (withdrawals []*Withdrawal, err error)
+		GetPendingBalanceWithdrawalByNftIndex(nftIndex int64) (withdrawal *Withdrawal, err error)
+		CreateWithdrawalsInTransact(tx *gorm.DB, withdrawals []*Withdrawal) error
+		UpdateWithdrawalsInTransact(tx *gorm.DB, withdrawals []*Withdrawal) error
+		DeleteWithdrawalsByL1TxHashesInTransact(tx *gorm.DB, txHashes []string) error
+		RevertWithdrawalClaimsInTransact(tx *gorm.DB, claimTxHashes []string) error
+	}
+
+	defaultWithdrawalModel struct {
+		table string
+		DB    *gorm.DB
+	}
+
+	// Withdrawal links an l2 withdraw, withdraw nft or full exit tx with the l1 events of the
+	// tx which executes its block and the tx which claims its pending balance.
+	Withdrawal struct {
+		gorm.Model
+		L2TxHash      string `gorm:"uniqueIndex"`
+		TxType        int64
+		AccountIndex  int64 `gorm:"index"`
+		L2BlockHeight int64
+		AssetId       int64
+		AssetAmount   string
+		NftIndex      int64
+		ToAddress     string `gorm:"index"`
+		Status        int    `gorm:"index"`
+		// hash of the l1 tx which executes the block
+		L1TxHash string `gorm:"index"`
+		// hash of the l1 tx which claims the pending balance
+		ClaimTxHash string `gorm:"index"`
+	}
+)
+
+func NewWithdrawalModel(db *gorm.DB) WithdrawalModel {
+	return &defaultWithdrawalModel{
+		table: TableName,
+		DB:    db,
+	}
+}
+
+func (*Withdrawal) TableName() string {
+	return TableName
+}
+
+func (m *defaultWithdrawalModel) CreateWithdrawalTable() error {
+	return m.DB.AutoMigrate(Withdrawal{})
+}
+
+func (m *defaultWithdrawalModel) DropWithdrawalTable() error {
+	return m.DB.Migrator().DropTable(m.table)
+}
+
+func (m *defaultWithdrawalModel) GetWithdrawalsByL2TxHashes(txHashes []string) (withdrawals []*Withdrawal, err error) {
+	dbTx := m.DB.Table(m.table).Where("l2_tx_hash IN ?", txHashes).Find(&withdrawals)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return withdrawals, nil
+}
+
+func (m *defaultWithdrawalModel) GetPendingBalanceWithdrawalsByAddress(toAddress string) (withdrawals []*Withdrawal, err error) {
+	dbTx := m.DB.Table(m.table).Where("to_address = ? AND status = ?", toAddress, StatusPendingBalance).
+		Order("id").Find(&withdrawals)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return withdrawals, nil
+}
+
+func (m *defaultWithdrawalModel) GetPendingBalanceWithdrawalByNftIndex(nftIndex int64) (withdrawal *Withdrawal, err error) {
+	dbTx := m.DB.Table(m.table).
+		Where("nft_index = ? AND tx_type IN ? AND status = ?", nftIndex,
+			[]int64{types.TxTypeWithdrawNft, types.TxTypeFullExitNft}, StatusPendingBalance).
+		Order("id").Limit(1).Find(&withdrawal)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return withdrawal, nil
+}
+
+func (m *defaultWithdrawalModel) CreateWithdrawalsInTransact(tx *gorm.DB, withdrawals []*Withdrawal) error {
+	dbTx := tx.Table(m.table).CreateInBatches(withdrawals, len(withdrawals))
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	if dbTx.RowsAffected != int64(len(withdrawals)) {
+		return types.DbErrFailToCreateWithdrawal
+	}
+	return nil
+}
+
+func (m *defaultWithdrawalModel) UpdateWithdrawalsInTransact(tx *gorm.DB, withdrawals []*Withdrawal) error {
+	for _, withdrawal := range withdrawals {
+		dbTx := tx.Table(m.table).Where("id = ?", withdrawal.ID).
+			Select("status", "claim_tx_hash").
+			Updates(withdrawal)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+		if dbTx.RowsAffected == 0 {
+			return types.DbErrFailToUpdateWithdrawal
+		}
+	}
+	return nil
+}
+
+func (m *defaultWithdrawalModel) DeleteWithdrawalsByL1TxHashesInTransact(tx *gorm.DB, txHashes []string) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("l1_tx_hash IN ?", txHashes).Delete(&Withdrawal{})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	return nil
+}
+
+func (m *defaultWithdrawalModel) RevertWithdrawalClaimsInTransact(tx *gorm.DB, claimTxHashes []string) error {
+	dbTx := tx.Table(m.table).Where("claim_tx_hash IN ?", claimTxHashes).
+		Updates(map[string]interface{}{"status": StatusPendingBalance, "claim_tx_hash": ""})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	return nil
+}
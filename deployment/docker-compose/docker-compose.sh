@@ -171,7 +171,6 @@ MemCache:
   AssetExpiration:   600
   BlockExpiration:   400
   TxExpiration:      400
-  PriceExpiration:   3600000
   MaxCounterNum:     100000
   MaxKeyNum:         10000
 
@@ -15,6 +15,8 @@ import (
 
 const (
 	cacheDefaultExpiration = time.Hour * 1 //gocache default expiration
+	// sys configs are short-lived, the gas fees in them are updated by the gas fee oracle
+	sysConfigExpiration = time.Minute
 
 	AccountIndexNameKeyPrefix  = "in:" //key for cache: accountIndex -> accountName
 	AccountIndexPkKeyPrefix    = "ip:" //key for cache: accountIndex -> accountPk
@@ -32,7 +34,6 @@ const (
 	AssetIdSymbolKeyPrefix     = "IS:" //key for cache: assetId -> assetName
 	AssetByIdKeyPrefix         = "I:"  //key for cache: assetId -> asset
 	AssetBySymbolKeyPrefix     = "S:"  //key for cache: assetSymbol -> asset
-	PriceKeyPrefix             = "p:"  //key for cache: symbol -> price
 	SysConfigKeyPrefix         = "s:"  //key for cache: configName -> sysconfig
 )
 
@@ -46,12 +47,11 @@ type MemCache struct {
 	blockExpiration   time.Duration
 	txExpiration      time.Duration
 	assetExpiration   time.Duration
-	priceExpiration   time.Duration
 }
 
 func MustNewMemCache(accountModel accdao.AccountModel, assetModel assetdao.AssetModel,
 	accountExpiration, blockExpiration, txExpiration,
-	assetExpiration, priceExpiration int, maxCounterNum, maxKeyNum int64) *MemCache {
+	assetExpiration int, maxCounterNum, maxKeyNum int64) *MemCache {
 
 	cache, err := ristretto.NewCache(&ristretto.Config{
 		NumCounters: maxCounterNum,
@@ -75,7 +75,6 @@ func MustNewMemCache(accountModel accdao.AccountModel, assetModel assetdao.Asset
 		blockExpiration:   time.Duration(blockExpiration) * time.Millisecond,
 		txExpiration:      time.Duration(txExpiration) * time.Millisecond,
 		assetExpiration:   time.Duration(assetExpiration) * time.Millisecond,
-		priceExpiration:   time.Duration(priceExpiration) * time.Millisecond,
 	}
 	return memCache
 }
@@ -299,25 +298,16 @@ func (m *MemCache) GetAssetSymbolById(assetId int64) (string, error) {
 	return asset.AssetSymbol, nil
 }
 
-func (m *MemCache) GetPriceWithFallback(symbol string, f fallback) (float64, error) {
-	key := fmt.Sprintf("%s%s", PriceKeyPrefix, symbol)
-	price, err := m.getWithSet(key, m.priceExpiration, f)
-	if err != nil {
-		return 0, err
-	}
-	return price.(float64), nil
-}
-
-func (m *MemCache) SetPrice(symbol string, price float64) {
-	key := fmt.Sprintf("%s%s", PriceKeyPrefix, symbol)
-	m.goCache.SetWithTTL(key, price, int64(len(key)), m.priceExpiration)
-}
-
 func (m *MemCache) GetSysConfigWithFallback(configName string, f fallback) (*sysconfig.SysConfig, error) {
 	key := fmt.Sprintf("%s%s", SysConfigKeyPrefix, configName)
-	c, err := m.getWithSet(key, cacheDefaultExpiration, f)
+	c, err := m.getWithSet(key, sysConfigExpiration, f)
 	if err != nil {
 		return nil, err
 	}
 	return c.(*sysconfig.SysConfig), nil
 }
+
+func (m *MemCache) SetSysConfig(config *sysconfig.SysConfig) {
+	key := fmt.Sprintf("%s%s", SysConfigKeyPrefix, config.Name)
+	m.goCache.SetWithTTL(key, config, 0, sysConfigExpiration)
+}
@@ -4,6 +4,10 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/rest"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/gasfee"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/price"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/zns"
 )
 
 type Config struct {
@@ -27,10 +31,15 @@ type Config struct {
 		AssetExpiration   int
 		BlockExpiration   int
 		TxExpiration      int
-		PriceExpiration   int
 		// Number of 4-bit access counters to keep for admission and eviction
 		// Setting this to 10x the number of items you expect to keep in the cache when full
 		MaxCounterNum int64
 		MaxKeyNum     int64
 	}
+	//nolint:staticcheck
+	PriceFeed price.Config `json:",optional"`
+	//nolint:staticcheck
+	GasFeeOracle gasfee.Config `json:",optional"`
+	//nolint:staticcheck
+	Zns zns.Config `json:",optional"`
 }
@@ -0,0 +1,367 @@
+package gasfee
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/common/rpcpool"
+	"github.com/bnb-chain/zkbnb/dao/asset"
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
+	"github.com/bnb-chain/zkbnb/dao/sysconfig"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/price"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	defaultUpdateInterval    = 300
+	defaultGasPerTx          = 20000
+	defaultHysteresisPercent = 10
+
+	fetchTimeout = 5 * time.Second
+
+	// Gas of each non-zero byte of calldata on l1.
+	calldataGasPerByte = 16
+	chunkSize          = 32
+	txChunks           = 6
+
+	// The fees of txs are packed into an 11 bits mantissa and a 5 bits exponent of 10.
+	packedFeeMaxMantissa = 2047
+)
+
+// maxGasFee is the largest packable fee which fits the int64 fees of the gas fee config.
+var maxGasFee = new(big.Int).Mul(big.NewInt(packedFeeMaxMantissa), big.NewInt(1e15))
+
+// pubDataSize is the size of the pubdata of each l2 tx type in the rollup calldata. The
+// pubdata of the on-chain operations is sent again when the block is verified.
+var pubDataSize = map[int]int{
+	types.TxTypeTransfer:         txChunks * chunkSize,
+	types.TxTypeWithdraw:         2 * txChunks * chunkSize,
+	types.TxTypeCreateCollection: txChunks * chunkSize,
+	types.TxTypeMintNft:          txChunks * chunkSize,
+	types.TxTypeTransferNft:      txChunks * chunkSize,
+	types.TxTypeAtomicMatch:      txChunks * chunkSize,
+	types.TxTypeCancelOffer:      txChunks * chunkSize,
+	types.TxTypeWithdrawNft:      2 * txChunks * chunkSize,
+}
+
+type Config struct {
+	// Only one api server of a deployment should run the oracle.
+	//nolint:staticcheck
+	Enabled bool `json:",optional"`
+	// The name of the sys config with the l1 rpc endpoints to fetch the gas price from.
+	//nolint:staticcheck
+	NetworkRPCSysConfigName string `json:",optional"`
+	//nolint:staticcheck
+	RPCPool rpcpool.Config `json:",optional"`
+	// Seconds between two updates of the gas fees.
+	//nolint:staticcheck
+	UpdateInterval int `json:",optional"`
+	// Gas of the rollup and verify txs shared by each tx on top of the calldata of its pubdata.
+	//nolint:staticcheck
+	GasPerTx uint64 `json:",optional"`
+	// Margin added to the l1 cost of a tx.
+	//nolint:staticcheck
+	FeeMarginPercent int64 `json:",optional"`
+	// Bounds of the fee of a tx in BNB wei, the fees in other assets are derived from them.
+	//nolint:staticcheck
+	MinFee int64 `json:",optional"`
+	//nolint:staticcheck
+	MaxFee int64 `json:",optional"`
+	// A fee is only updated if it moves by more than the percent.
+	//nolint:staticcheck
+	HysteresisPercent int64 `json:",optional"`
+}
+
+// Source is the data the gas fees are derived from, it is published with the fees.
+type Source struct {
+	L1GasPrice  string             `json:"l1_gas_price"`
+	GasPerTx    uint64             `json:"gas_per_tx"`
+	PubDataSize map[int]int        `json:"pub_data_size"`
+	Prices      map[uint32]float64 `json:"prices"`
+	UpdatedAt   int64              `json:"updated_at"`
+}
+
+type Oracle interface {
+	Stop()
+}
+
+func NewOracle(config Config, db *gorm.DB, sysConfigModel sysconfig.SysConfigModel, assetModel asset.AssetModel,
+	priceFetcher price.Fetcher, redisCache dbcache.Cache, memCache *cache.MemCache) (Oracle, error) {
+	if config.UpdateInterval <= 0 {
+		config.UpdateInterval = defaultUpdateInterval
+	}
+	if config.GasPerTx == 0 {
+		config.GasPerTx = defaultGasPerTx
+	}
+	if config.HysteresisPercent <= 0 {
+		config.HysteresisPercent = defaultHysteresisPercent
+	}
+	if config.MaxFee > 0 && config.MinFee > config.MaxFee {
+		return nil, fmt.Errorf("min fee %d is larger than max fee %d", config.MinFee, config.MaxFee)
+	}
+
+	l1RPCEndpoint, err := sysConfigModel.GetSysConfigByName(config.NetworkRPCSysConfigName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get l1 rpc endpoint, err: %v, name: %s", err, config.NetworkRPCSysConfigName)
+	}
+	rpcPool, err := rpcpool.NewPool(rpcpool.ParseEndpoints(l1RPCEndpoint.Value), config.RPCPool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rpc pool, err: %v", err)
+	}
+
+	o := &oracle{
+		config:         config,
+		db:             db,
+		sysConfigModel: sysConfigModel,
+		assetModel:     assetModel,
+		priceFetcher:   priceFetcher,
+		redisCache:     redisCache,
+		memCache:       memCache,
+		rpcPool:        rpcPool,
+		quitCh:         make(chan struct{}),
+	}
+	go o.loop()
+	return o, nil
+}
+
+type oracle struct {
+	config         Config
+	db             *gorm.DB
+	sysConfigModel sysconfig.SysConfigModel
+	assetModel     asset.AssetModel
+	priceFetcher   price.Fetcher
+	redisCache     dbcache.Cache
+	memCache       *cache.MemCache
+	rpcPool        *rpcpool.Pool
+
+	quitCh chan struct{}
+}
+
+func (o *oracle) loop() {
+	ticker := time.NewTicker(time.Duration(o.config.UpdateInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if err := o.update(); err != nil {
+			logx.Errorf("failed to update gas fees, err: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-o.quitCh:
+			return
+		}
+	}
+}
+
+func (o *oracle) Stop() {
+	close(o.quitCh)
+	o.rpcPool.Stop()
+}
+
+func (o *oracle) update() error {
+	gasFeeConfig, err := o.sysConfigModel.GetSysConfigByName(types.SysGasFee)
+	if err != nil {
+		return fmt.Errorf("failed to get gas fee config, err: %v", err)
+	}
+	fees := make(map[uint32]map[int]int64)
+	err = json.Unmarshal([]byte(gasFeeConfig.Value), &fees)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal gas fee config, err: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	gasPrice, err := o.rpcPool.Client().SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch l1 gas price, err: %v", err)
+	}
+	source := &Source{
+		L1GasPrice:  gasPrice.String(),
+		GasPerTx:    o.config.GasPerTx,
+		PubDataSize: pubDataSize,
+		Prices:      make(map[uint32]float64),
+		UpdatedAt:   time.Now().UnixMilli(),
+	}
+	decimals := make(map[uint32]uint32)
+	for _, assetId := range append([]uint32{types.BNBAssetId}, assetIds(fees)...) {
+		if _, ok := source.Prices[assetId]; ok {
+			continue
+		}
+		gasAsset, err := o.assetModel.GetAssetById(int64(assetId))
+		if err != nil {
+			return fmt.Errorf("failed to get asset %d, err: %v", assetId, err)
+		}
+		assetPrice, err := o.priceFetcher.GetCurrencyPrice(ctx, gasAsset.AssetSymbol)
+		if err != nil {
+			logx.Errorf("failed to get price of %s, err: %v", gasAsset.AssetSymbol, err)
+			continue
+		}
+		source.Prices[assetId] = assetPrice
+		decimals[assetId] = gasAsset.Decimals
+	}
+
+	changed := computeGasFees(o.config, source, decimals, fees)
+	return o.save(fees, changed, source, gasFeeConfig)
+}
+
+func assetIds(fees map[uint32]map[int]int64) []uint32 {
+	ids := make([]uint32, 0, len(fees))
+	for assetId := range fees {
+		ids = append(ids, assetId)
+	}
+	return ids
+}
+
+// computeGasFees updates the fees of the gas assets with known prices from the l1 cost of
+// each tx type, and returns whether any fee is changed. The fees are rounded up to packable
+// amounts, and a fee is kept if it moves by no more than the hysteresis percent.
+func computeGasFees(config Config, source *Source, decimals map[uint32]uint32, fees map[uint32]map[int]int64) bool {
+	gasPrice, ok := new(big.Int).SetString(source.L1GasPrice, 10)
+	bnbPrice := source.Prices[types.BNBAssetId]
+	if !ok || bnbPrice <= 0 {
+		return false
+	}
+
+	changed := false
+	for assetId, assetFees := range fees {
+		assetPrice := source.Prices[assetId]
+		if assetPrice <= 0 {
+			continue
+		}
+		for txType, size := range source.PubDataSize {
+			gas := new(big.Int).SetUint64(source.GasPerTx + uint64(size*calldataGasPerByte))
+			cost := new(big.Int).Mul(gas, gasPrice)
+			cost.Mul(cost, big.NewInt(100+config.FeeMarginPercent))
+			cost.Div(cost, big.NewInt(100))
+			if config.MinFee > 0 && cost.Cmp(big.NewInt(config.MinFee)) < 0 {
+				cost.SetInt64(config.MinFee)
+			}
+			if config.MaxFee > 0 && cost.Cmp(big.NewInt(config.MaxFee)) > 0 {
+				cost.SetInt64(config.MaxFee)
+			}
+
+			// BNB wei to the smallest unit of the asset
+			fee := new(big.Float).SetInt(cost)
+			fee.Mul(fee, big.NewFloat(bnbPrice/assetPrice))
+			fee.Mul(fee, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals[assetId])), nil)))
+			fee.Quo(fee, big.NewFloat(1e18))
+			if fee.IsInf() || fee.Sign() <= 0 {
+				continue
+			}
+			feeInt, accuracy := fee.Int(nil)
+			if accuracy == big.Below {
+				feeInt.Add(feeInt, big.NewInt(1))
+			}
+			// the fee is rounded up to the amount debited with the packed fee
+			feeInt = roundUpToPackedFee(feeInt)
+			if feeInt.Cmp(maxGasFee) > 0 {
+				logx.Errorf("gas fee of tx type %d in asset %d is too large: %s, use %s", txType, assetId, feeInt, maxGasFee)
+				feeInt.Set(maxGasFee)
+			}
+			if !feeInt.IsInt64() || feeInt.Sign() <= 0 {
+				continue
+			}
+			newFee := feeInt.Int64()
+
+			// the fees which are not packable are replaced at once
+			oldFee, ok := assetFees[txType]
+			if ok && roundUpToPackedFee(big.NewInt(oldFee)).Cmp(big.NewInt(oldFee)) == 0 &&
+				withinHysteresis(newFee, oldFee, config.HysteresisPercent) {
+				continue
+			}
+			logx.Infof("gas fee of tx type %d in asset %d changes from %d to %d", txType, assetId, oldFee, newFee)
+			assetFees[txType] = newFee
+			changed = true
+		}
+	}
+	return changed
+}
+
+// withinHysteresis returns whether the new fee moves by no more than the percent of the old fee.
+func withinHysteresis(newFee, oldFee int64, percent int64) bool {
+	diff := new(big.Int).Sub(big.NewInt(newFee), big.NewInt(oldFee))
+	diff.Abs(diff).Mul(diff, big.NewInt(100))
+	return diff.Cmp(new(big.Int).Mul(big.NewInt(oldFee), big.NewInt(percent))) <= 0
+}
+
+// roundUpToPackedFee returns the smallest amount not less than the fee which is exactly
+// represented by a packed fee, whose mantissa is at most 2047.
+func roundUpToPackedFee(fee *big.Int) *big.Int {
+	mantissa := new(big.Int).Set(fee)
+	exponent := int64(0)
+	ten := big.NewInt(10)
+	for mantissa.Cmp(big.NewInt(packedFeeMaxMantissa)) > 0 {
+		quotient, remainder := new(big.Int).QuoRem(mantissa, ten, new(big.Int))
+		if remainder.Sign() > 0 {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+		mantissa = quotient
+		exponent++
+	}
+	return mantissa.Mul(mantissa, new(big.Int).Exp(ten, big.NewInt(exponent), nil))
+}
+
+func (o *oracle) save(fees map[uint32]map[int]int64, changed bool, source *Source, gasFeeConfig *sysconfig.SysConfig) error {
+	sourceValue, err := json.Marshal(source)
+	if err != nil {
+		return fmt.Errorf("failed to marshal gas fee source, err: %v", err)
+	}
+	sourceConfig, err := o.sysConfigModel.GetSysConfigByName(types.SysGasFeeSource)
+	if err != nil && err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get gas fee source, err: %v", err)
+	}
+	createSource := err == types.DbErrNotFound
+	if createSource {
+		sourceConfig = &sysconfig.SysConfig{
+			Name:      types.SysGasFeeSource,
+			ValueType: "string",
+			Comment:   "source data of the gas fees",
+		}
+	}
+	sourceConfig.Value = string(sourceValue)
+
+	updates := []*sysconfig.SysConfig{}
+	if !createSource {
+		updates = append(updates, sourceConfig)
+	}
+	if changed {
+		value, err := json.Marshal(fees)
+		if err != nil {
+			return fmt.Errorf("failed to marshal gas fee config, err: %v", err)
+		}
+		gasFeeConfig.Value = string(value)
+		updates = append(updates, gasFeeConfig)
+	}
+	err = o.db.Transaction(func(tx *gorm.DB) error {
+		if createSource {
+			err := o.sysConfigModel.CreateSysConfigsInTransact(tx, []*sysconfig.SysConfig{sourceConfig})
+			if err != nil {
+				return err
+			}
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return o.sysConfigModel.UpdateSysConfigsInTransact(tx, updates)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save gas fees, err: %v", err)
+	}
+
+	o.memCache.SetSysConfig(sourceConfig)
+	if changed {
+		o.memCache.SetSysConfig(gasFeeConfig)
+		// The committer caches the gas fees in redis.
+		err = o.redisCache.Delete(context.Background(), dbcache.GasConfigKey)
+		if err != nil {
+			logx.Errorf("failed to delete cached gas fees, err: %v", err)
+		}
+	}
+	return nil
+}
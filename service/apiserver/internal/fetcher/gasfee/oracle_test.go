@@ -0,0 +1,91 @@
+package gasfee
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/common"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestComputeGasFees(t *testing.T) {
+	config := Config{GasPerTx: 20000, HysteresisPercent: 10}
+	source := &Source{
+		L1GasPrice:  "5000000000", // 5 gwei
+		GasPerTx:    config.GasPerTx,
+		PubDataSize: map[int]int{types.TxTypeTransfer: 192, types.TxTypeWithdraw: 384},
+		Prices:      map[uint32]float64{types.BNBAssetId: 300, types.BUSDAssetId: 1},
+	}
+	decimals := map[uint32]uint32{types.BNBAssetId: 18, types.BUSDAssetId: 18}
+	fees := map[uint32]map[int]int64{
+		types.BNBAssetId:  {types.TxTypeTransfer: 1, types.TxTypeWithdraw: 1},
+		types.BUSDAssetId: {types.TxTypeTransfer: 1, types.TxTypeWithdraw: 1},
+	}
+
+	assert.True(t, computeGasFees(config, source, decimals, fees))
+	// (20000 + 192 * 16) gas * 5 gwei, rounded up to packable amounts
+	assert.Equal(t, int64(115400000000000), fees[types.BNBAssetId][types.TxTypeTransfer])
+	assert.Equal(t, int64(130800000000000), fees[types.BNBAssetId][types.TxTypeWithdraw])
+	assert.Equal(t, int64(34700000000000000), fees[types.BUSDAssetId][types.TxTypeTransfer])
+
+	// moves within the hysteresis
+	source.L1GasPrice = "5400000000"
+	assert.False(t, computeGasFees(config, source, decimals, fees))
+	assert.Equal(t, int64(115400000000000), fees[types.BNBAssetId][types.TxTypeTransfer])
+
+	// bounded
+	config.MaxFee = 100000000000000
+	source.L1GasPrice = "10000000000"
+	assert.True(t, computeGasFees(config, source, decimals, fees))
+	assert.Equal(t, config.MaxFee, fees[types.BNBAssetId][types.TxTypeTransfer])
+	assert.Equal(t, config.MaxFee, fees[types.BNBAssetId][types.TxTypeWithdraw])
+
+	// the fees of assets without prices are kept
+	delete(source.Prices, types.BUSDAssetId)
+	busdFee := fees[types.BUSDAssetId][types.TxTypeTransfer]
+	config.MaxFee = 0
+	assert.True(t, computeGasFees(config, source, decimals, fees))
+	assert.Equal(t, busdFee, fees[types.BUSDAssetId][types.TxTypeTransfer])
+}
+
+func TestComputeGasFeesOfCheapAsset(t *testing.T) {
+	config := Config{GasPerTx: 20000, HysteresisPercent: 10}
+	source := &Source{
+		L1GasPrice:  "5000000000",
+		GasPerTx:    config.GasPerTx,
+		PubDataSize: map[int]int{types.TxTypeTransfer: 192},
+		Prices:      map[uint32]float64{types.BNBAssetId: 300, 3: 1e-8, 4: 1},
+	}
+	decimals := map[uint32]uint32{types.BNBAssetId: 18, 3: 18, 4: 18}
+	fees := map[uint32]map[int]int64{
+		3: {types.TxTypeTransfer: 1},
+		// not packable
+		4: {types.TxTypeTransfer: 34608000000000001},
+	}
+
+	// the fee does not fit int64, it is clamped to the largest packable fee
+	assert.True(t, computeGasFees(config, source, decimals, fees))
+	assert.Equal(t, maxGasFee.Int64(), fees[3][types.TxTypeTransfer])
+	// the fee within the hysteresis is replaced since it is not packable
+	assert.Equal(t, int64(34700000000000000), fees[4][types.TxTypeTransfer])
+	for _, assetFees := range fees {
+		fee := big.NewInt(assetFees[types.TxTypeTransfer])
+		_, err := common.ToPackedFee(fee)
+		assert.NoError(t, err)
+		assert.Equal(t, fee, roundUpToPackedFee(fee))
+	}
+
+	// the moves of large fees do not overflow
+	source.Prices[3] = 2e-8
+	assert.False(t, computeGasFees(config, source, decimals, fees))
+}
+
+func TestRoundUpToPackedFee(t *testing.T) {
+	assert.Equal(t, int64(2047), roundUpToPackedFee(big.NewInt(2047)).Int64())
+	assert.Equal(t, int64(2050), roundUpToPackedFee(big.NewInt(2048)).Int64())
+	assert.Equal(t, int64(20500), roundUpToPackedFee(big.NewInt(20471)).Int64())
+	assert.Equal(t, int64(1235000), roundUpToPackedFee(big.NewInt(1234567)).Int64())
+	assert.Equal(t, int64(1230000), roundUpToPackedFee(big.NewInt(1230000)).Int64())
+}
@@ -2,148 +2,257 @@ package price
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"github.com/bnb-chain/zkbnb/dao/asset"
-	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
-	"github.com/bnb-chain/zkbnb/types"
 )
 
 const (
-	fetchTimeout  = 3 * time.Second
-	fetchLimit    = 100
-	fetchInterval = 60 * time.Minute
+	// timeout of fetching the price of a symbol from a source
+	fetchTimeout = 3 * time.Second
+	fetchLimit   = 100
+
+	defaultFetchInterval = 60
+	defaultMaxAge        = 300
 )
 
+type Config struct {
+	// Seconds between two refreshes of the prices of all assets.
+	//nolint:staticcheck
+	FetchInterval int `json:",optional"`
+	// Seconds after which the price of a source is stale and not used any more.
+	//nolint:staticcheck
+	MaxAge int `json:",optional"`
+	// Max ages of some symbols, such as stablecoins which are not refreshed as often.
+	//nolint:staticcheck
+	SymbolMaxAge map[string]int `json:",optional"`
+	// The price of a symbol is the median of the fresh prices of the sources. The coinmarketcap
+	// config is the only source if no source is configured.
+	//nolint:staticcheck
+	Sources []SourceConfig `json:",optional"`
+}
+
+// Price is the price of a symbol with the prices of the sources it is the median of.
+type Price struct {
+	Symbol    string
+	Price     float64
+	UpdatedAt time.Time
+	Sources   []*SourcePrice
+}
+
+type SourcePrice struct {
+	Source    string
+	Price     float64
+	UpdatedAt time.Time
+	Stale     bool
+}
+
 type Fetcher interface {
 	GetCurrencyPrice(ctx context.Context, l2Symbol string) (price float64, err error)
+	// GetPrices returns the prices of all the symbols fetched.
+	GetPrices() []*Price
 	Stop()
 }
 
-func NewFetcher(memCache *cache.MemCache, assetModel asset.AssetModel, cmcUrl, cmcToken string) Fetcher {
+func NewFetcher(assetModel asset.AssetModel, config Config, cmcUrl, cmcToken string) (Fetcher, error) {
+	if config.FetchInterval <= 0 {
+		config.FetchInterval = defaultFetchInterval
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = defaultMaxAge
+	}
+	sourceConfigs := config.Sources
+	if len(sourceConfigs) == 0 {
+		sourceConfigs = []SourceConfig{{Type: CoinMarketCap, Url: cmcUrl, Token: cmcToken}}
+	}
 	f := &fetcher{
-		memCache:   memCache,
 		assetModel: assetModel,
-		cmcUrl:     cmcUrl,
-		cmcToken:   cmcToken,
+		config:     config,
+		prices:     make(map[string]map[string]SymbolPrice),
+		attempts:   make(map[string]time.Time),
 		quitCh:     make(chan struct{}),
 	}
+	names := make(map[string]bool)
+	for _, sourceConfig := range sourceConfigs {
+		source, err := NewPriceSource(sourceConfig)
+		if err != nil {
+			return nil, err
+		}
+		if names[source.Name()] {
+			return nil, fmt.Errorf("duplicate price source %s", source.Name())
+		}
+		names[source.Name()] = true
+		f.sources = append(f.sources, source)
+	}
 	go f.loop()
-	return f
+	return f, nil
 }
 
 type fetcher struct {
-	memCache   *cache.MemCache
 	assetModel asset.AssetModel
-	cmcUrl     string
-	cmcToken   string
+	config     Config
+	sources    []PriceSource
+
+	lock sync.RWMutex
+	// symbol -> source -> price
+	prices map[string]map[string]SymbolPrice
+	// the latest fetches of the symbols on demand
+	attempts map[string]time.Time
 
 	quitCh chan struct{}
 }
 
 func (f *fetcher) loop() {
-	ticker := time.NewTicker(fetchInterval)
+	ticker := time.NewTicker(time.Duration(f.config.FetchInterval) * time.Second)
 	defer ticker.Stop()
 	for {
+		f.refresh()
 		select {
 		case <-ticker.C:
-			total, err := f.assetModel.GetAssetsTotalCount()
-			if err != nil {
-				logx.Errorf("failed to get all assets, err: %v", err)
-				continue
-			}
-			for i := 0; i < int(total); i += fetchLimit {
-				assets, err := f.assetModel.GetAssets(int64(fetchLimit), int64(i))
-				if err != nil {
-					logx.Errorf("failed to get all assets, err: %v", err)
-					continue
-				}
-
-				for _, asset := range assets {
-					func() {
-						ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
-						defer cancel()
-						assetPrice, err := f.GetCurrencyPrice(ctx, asset.AssetSymbol)
-						if err != nil {
-							logx.Errorf("failed to get all assets, err: %v", err)
-							return
-						}
-						f.memCache.SetPrice(asset.AssetSymbol, assetPrice)
-					}()
-				}
-			}
 		case <-f.quitCh:
 			return
 		}
 	}
 }
 
-func (f *fetcher) Stop() {
-	close(f.quitCh)
-}
-
-func (f *fetcher) GetCurrencyPrice(_ context.Context, symbol string) (float64, error) {
-	return f.memCache.GetPriceWithFallback(symbol, func() (interface{}, error) {
-		quoteMap, err := f.getLatestQuotes(symbol)
+func (f *fetcher) refresh() {
+	total, err := f.assetModel.GetAssetsTotalCount()
+	if err != nil {
+		logx.Errorf("failed to get all assets, err: %v", err)
+		return
+	}
+	var symbols []string
+	for i := 0; i < int(total); i += fetchLimit {
+		assets, err := f.assetModel.GetAssets(int64(fetchLimit), int64(i))
 		if err != nil {
-			if err == types.CmcNotListedErr {
-				return 0.0, nil
-			}
-			return 0.0, err
+			logx.Errorf("failed to get all assets, err: %v", err)
+			return
 		}
-		q, ok := quoteMap[symbol]
-		if !ok {
-			return 0.0, nil
+		for _, asset := range assets {
+			symbols = append(symbols, asset.AssetSymbol)
 		}
-		price := q.Quote["USD"].Price
-		return price, nil
-	})
+	}
+	f.fetch(context.Background(), symbols)
 }
 
-func (f *fetcher) getLatestQuotes(symbol string) (map[string]QuoteLatest, error) {
-	client := &http.Client{}
-	url := fmt.Sprintf("%s%s", f.cmcUrl, symbol)
-	request, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, types.HttpErrFailToRequest
+// fetch gets the prices of the symbols from all the sources concurrently. The prices of a
+// failed source are kept until they are stale.
+func (f *fetcher) fetch(ctx context.Context, symbols []string) {
+	var wg sync.WaitGroup
+	for _, source := range f.sources {
+		wg.Add(1)
+		go func(source PriceSource) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, time.Duration(len(symbols))*fetchTimeout)
+			defer cancel()
+			prices, err := source.GetPrices(ctx, symbols)
+			if err != nil {
+				logx.Errorf("failed to get prices from %s, err: %v", source.Name(), err)
+				return
+			}
+			f.lock.Lock()
+			defer f.lock.Unlock()
+			for symbol, price := range prices {
+				if f.prices[symbol] == nil {
+					f.prices[symbol] = make(map[string]SymbolPrice)
+				}
+				f.prices[symbol][source.Name()] = price
+			}
+		}(source)
 	}
-	request.Header.Add("X-CMC_PRO_API_KEY", f.cmcToken)
-	request.Header.Add("Accept", "application/json")
-	resp, err := client.Do(request)
-	if err != nil {
-		return nil, types.HttpErrClientDo
+	wg.Wait()
+}
+
+func (f *fetcher) Stop() {
+	close(f.quitCh)
+}
+
+// GetCurrencyPrice returns the median of the fresh prices of the symbol, which is fetched on
+// demand if it has no fresh price. The price is 0 if no source knows the symbol.
+func (f *fetcher) GetCurrencyPrice(ctx context.Context, symbol string) (float64, error) {
+	if price := f.getPrice(symbol); price.Price > 0 {
+		return price.Price, nil
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, types.IoErrFailToRead
+
+	// Fetch at most once per interval, the symbol may be unknown to all the sources.
+	f.lock.Lock()
+	attempt, ok := f.attempts[symbol]
+	if ok && time.Since(attempt) < time.Duration(f.config.FetchInterval)*time.Second {
+		f.lock.Unlock()
+		return 0, nil
+	}
+	f.attempts[symbol] = time.Now()
+	f.lock.Unlock()
+
+	f.fetch(ctx, []string{symbol})
+	return f.getPrice(symbol).Price, nil
+}
+
+func (f *fetcher) GetPrices() []*Price {
+	f.lock.RLock()
+	symbols := make([]string, 0, len(f.prices))
+	for symbol := range f.prices {
+		symbols = append(symbols, symbol)
 	}
-	currencyPrice := &currencyPrice{}
-	if err = json.Unmarshal(body, &currencyPrice); err != nil {
-		return nil, types.JsonErrUnmarshal
+	f.lock.RUnlock()
+	sort.Strings(symbols)
+
+	prices := make([]*Price, 0, len(symbols))
+	for _, symbol := range symbols {
+		prices = append(prices, f.getPrice(symbol))
 	}
-	dataMap, ok := currencyPrice.Data.(map[string]interface{})
-	if !ok { //the currency not listed on cmc
-		return nil, types.CmcNotListedErr
+	return prices
+}
+
+func (f *fetcher) getPrice(symbol string) *Price {
+	maxAge := time.Duration(f.config.MaxAge) * time.Second
+	if symbolMaxAge, ok := f.config.SymbolMaxAge[symbol]; ok {
+		maxAge = time.Duration(symbolMaxAge) * time.Second
 	}
-	quotesLatest := make(map[string]QuoteLatest, 0)
-	for _, coinObj := range dataMap {
-		b, err := json.Marshal(coinObj)
-		if err != nil {
-			return nil, types.JsonErrMarshal
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return aggregate(symbol, f.prices[symbol], maxAge, time.Now())
+}
+
+// aggregate takes the median of the prices of the sources updated within maxAge.
+func aggregate(symbol string, sourcePrices map[string]SymbolPrice, maxAge time.Duration, now time.Time) *Price {
+	price := &Price{Symbol: symbol}
+	var fresh []float64
+	for source, sourcePrice := range sourcePrices {
+		stale := now.Sub(sourcePrice.UpdatedAt) > maxAge
+		price.Sources = append(price.Sources, &SourcePrice{
+			Source:    source,
+			Price:     sourcePrice.Price,
+			UpdatedAt: sourcePrice.UpdatedAt,
+			Stale:     stale,
+		})
+		if stale || sourcePrice.Price <= 0 {
+			continue
 		}
-		quoteLatest := &QuoteLatest{}
-		err = json.Unmarshal(b, quoteLatest)
-		if err != nil {
-			return nil, types.JsonErrUnmarshal
+		fresh = append(fresh, sourcePrice.Price)
+		if sourcePrice.UpdatedAt.After(price.UpdatedAt) {
+			price.UpdatedAt = sourcePrice.UpdatedAt
 		}
-		quotesLatest[quoteLatest.Symbol] = *quoteLatest
 	}
-	return quotesLatest, nil
+	sort.Slice(price.Sources, func(i, j int) bool {
+		return price.Sources[i].Source < price.Sources[j].Source
+	})
+	if len(fresh) == 0 {
+		return price
+	}
+
+	sort.Float64s(fresh)
+	middle := len(fresh) / 2
+	if len(fresh)%2 == 1 {
+		price.Price = fresh[middle]
+	} else {
+		price.Price = (fresh[middle-1] + fresh[middle]) / 2
+	}
+	return price
 }
@@ -0,0 +1,112 @@
+package price
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	now := time.Now()
+	prices := map[string]SymbolPrice{
+		"a": {Price: 300, UpdatedAt: now.Add(-time.Minute)},
+		"b": {Price: 310, UpdatedAt: now},
+		"c": {Price: 1000, UpdatedAt: now.Add(-time.Hour)},
+	}
+
+	// the stale price is left out
+	price := aggregate("BNB", prices, 5*time.Minute, now)
+	assert.Equal(t, 305.0, price.Price)
+	assert.Equal(t, now, price.UpdatedAt)
+	require.Len(t, price.Sources, 3)
+	assert.Equal(t, "c", price.Sources[2].Source)
+	assert.True(t, price.Sources[2].Stale)
+
+	price = aggregate("BNB", prices, 2*time.Hour, now)
+	assert.Equal(t, 310.0, price.Price)
+
+	// no fresh price
+	price = aggregate("BNB", prices, time.Second, now.Add(2*time.Minute))
+	assert.Equal(t, 0.0, price.Price)
+	assert.True(t, price.UpdatedAt.IsZero())
+}
+
+func TestFileSource(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{"BUSD": 1, "BNB": 300.5}`), 0644))
+	source, err := NewPriceSource(SourceConfig{Type: File, File: file})
+	require.NoError(t, err)
+	assert.Equal(t, "file", source.Name())
+
+	prices, err := source.GetPrices(context.Background(), []string{"BNB", "ETH"})
+	require.NoError(t, err)
+	assert.Len(t, prices, 1)
+	assert.Equal(t, 300.5, prices["BNB"].Price)
+}
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		switch r.URL.Query().Get("symbol") {
+		case "BNB":
+			_, _ = w.Write([]byte(`{"data": [{"price": "300.5", "time": 1666000000}]}`))
+		case "BUSD":
+			_, _ = w.Write([]byte(`{"data": []}`))
+		case "USDT":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewPriceSource(SourceConfig{Type: HTTP, Url: server.URL, PricePath: "price"})
+	assert.Error(t, err)
+	source, err := NewPriceSource(SourceConfig{
+		Type:          HTTP,
+		Name:          "test",
+		Url:           server.URL + "?symbol={symbol}",
+		PricePath:     "data.0.price",
+		TimestampPath: "data.0.time",
+		Headers:       map[string]string{"X-Api-Key": "key"},
+	})
+	require.NoError(t, err)
+
+	// the failing symbols are skipped
+	prices, err := source.GetPrices(context.Background(), []string{"USDT", "BNB", "BUSD", "ETH"})
+	require.NoError(t, err)
+	assert.Len(t, prices, 1)
+	assert.Equal(t, 300.5, prices["BNB"].Price)
+	assert.Equal(t, time.Unix(1666000000, 0), prices["BNB"].UpdatedAt)
+}
+
+func TestCmcSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "BNB,ETH,XYZ", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "true", r.URL.Query().Get("skip_invalid"))
+		_, _ = w.Write([]byte(`{"data": {
+			"BNB": {"symbol": "BNB", "quote": {"USD": {"price": 300.5, "last_updated": "2022-10-17T09:46:40Z"}}},
+			"ETH": {"symbol": "ETH", "quote": {"USD": {"price": 1300}}}
+		}}`))
+	}))
+	defer server.Close()
+
+	source, err := NewPriceSource(SourceConfig{Type: CoinMarketCap, Url: server.URL + "?symbol="})
+	require.NoError(t, err)
+	prices, err := source.GetPrices(context.Background(), []string{"BNB", "ETH", "XYZ"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Len(t, prices, 2)
+	assert.Equal(t, 300.5, prices["BNB"].Price)
+	assert.Equal(t, time.Date(2022, 10, 17, 9, 46, 40, 0, time.UTC), prices["BNB"].UpdatedAt)
+	assert.Equal(t, 1300.0, prices["ETH"].Price)
+}
@@ -0,0 +1,322 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+type SourceType string
+
+const (
+	CoinMarketCap SourceType = "coinmarketcap"
+	File          SourceType = "file"
+	HTTP          SourceType = "http"
+)
+
+// SymbolPrice is the usd price of a symbol from a source, with the time it is updated at.
+type SymbolPrice struct {
+	Price     float64
+	UpdatedAt time.Time
+}
+
+// PriceSource provides the usd prices of symbols. The symbols it does not know are left out.
+type PriceSource interface {
+	Name() string
+	GetPrices(ctx context.Context, symbols []string) (map[string]SymbolPrice, error)
+}
+
+type SourceConfig struct {
+	Type SourceType
+	// Defaults to the type.
+	//nolint:staticcheck
+	Name string `json:",optional"`
+	// The url of the coinmarketcap and http sources, {symbol} in the url of the http source is
+	// replaced with the symbol.
+	//nolint:staticcheck
+	Url string `json:",optional"`
+	// The api key of the coinmarketcap source.
+	//nolint:staticcheck
+	Token string `json:",optional"`
+	// The json file of the file source, which maps symbols to prices.
+	//nolint:staticcheck
+	File string `json:",optional"`
+	// The dot separated paths of the price and the update time in the responses of the http
+	// source, such as data.price. The update time can be unix seconds or RFC3339.
+	//nolint:staticcheck
+	PricePath string `json:",optional"`
+	//nolint:staticcheck
+	TimestampPath string `json:",optional"`
+	//nolint:staticcheck
+	Headers map[string]string `json:",optional"`
+}
+
+func NewPriceSource(config SourceConfig) (PriceSource, error) {
+	name := config.Name
+	if name == "" {
+		name = string(config.Type)
+	}
+	switch config.Type {
+	case CoinMarketCap:
+		return &cmcSource{name: name, url: config.Url, token: config.Token}, nil
+	case File:
+		if config.File == "" {
+			return nil, fmt.Errorf("no file of price source %s", name)
+		}
+		return &fileSource{name: name, file: config.File}, nil
+	case HTTP:
+		if !strings.Contains(config.Url, "{symbol}") || config.PricePath == "" {
+			return nil, fmt.Errorf("price source %s needs a url with {symbol} and a price path", name)
+		}
+		return &httpSource{name: name, url: config.Url, pricePath: config.PricePath,
+			timestampPath: config.TimestampPath, headers: config.Headers}, nil
+	}
+	return nil, fmt.Errorf("unknown type of price source %s: %s", name, config.Type)
+}
+
+// cmcSource fetches the latest quotes of all the symbols from coinmarketcap in one request,
+// the symbols coinmarketcap does not know are skipped.
+type cmcSource struct {
+	name  string
+	url   string
+	token string
+}
+
+func (s *cmcSource) Name() string {
+	return s.name
+}
+
+func (s *cmcSource) GetPrices(ctx context.Context, symbols []string) (map[string]SymbolPrice, error) {
+	prices := make(map[string]SymbolPrice)
+	if len(symbols) == 0 {
+		return prices, nil
+	}
+	quoteMap, err := s.getLatestQuotes(ctx, symbols)
+	if err == types.CmcNotListedErr {
+		return prices, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range symbols {
+		q, ok := quoteMap[symbol]
+		if !ok {
+			continue
+		}
+		updatedAt, err := time.Parse(time.RFC3339, q.Quote["USD"].LastUpdated)
+		if err != nil {
+			updatedAt = time.Now()
+		}
+		prices[symbol] = SymbolPrice{Price: q.Quote["USD"].Price, UpdatedAt: updatedAt}
+	}
+	return prices, nil
+}
+
+func (s *cmcSource) getLatestQuotes(ctx context.Context, symbols []string) (map[string]QuoteLatest, error) {
+	client := &http.Client{}
+	url := fmt.Sprintf("%s%s&skip_invalid=true", s.url, strings.Join(symbols, ","))
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, types.HttpErrFailToRequest
+	}
+	request.Header.Add("X-CMC_PRO_API_KEY", s.token)
+	request.Header.Add("Accept", "application/json")
+	resp, err := client.Do(request)
+	if err != nil {
+		return nil, types.HttpErrClientDo
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, types.IoErrFailToRead
+	}
+	currencyPrice := &currencyPrice{}
+	if err = json.Unmarshal(body, &currencyPrice); err != nil {
+		return nil, types.JsonErrUnmarshal
+	}
+	dataMap, ok := currencyPrice.Data.(map[string]interface{})
+	if !ok { //the currency not listed on cmc
+		return nil, types.CmcNotListedErr
+	}
+	quotesLatest := make(map[string]QuoteLatest, 0)
+	for _, coinObj := range dataMap {
+		b, err := json.Marshal(coinObj)
+		if err != nil {
+			return nil, types.JsonErrMarshal
+		}
+		quoteLatest := &QuoteLatest{}
+		err = json.Unmarshal(b, quoteLatest)
+		if err != nil {
+			return nil, types.JsonErrUnmarshal
+		}
+		quotesLatest[quoteLatest.Symbol] = *quoteLatest
+	}
+	return quotesLatest, nil
+}
+
+// fileSource reads the prices from a json file which maps symbols to prices, such as
+// {"BUSD": 1}. The file is read on each fetch, and its prices never go stale.
+type fileSource struct {
+	name string
+	file string
+}
+
+func (s *fileSource) Name() string {
+	return s.name
+}
+
+func (s *fileSource) GetPrices(_ context.Context, symbols []string) (map[string]SymbolPrice, error) {
+	bz, err := ioutil.ReadFile(s.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file, err: %v", err)
+	}
+	filePrices := make(map[string]float64)
+	err = json.Unmarshal(bz, &filePrices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal price file, err: %v", err)
+	}
+	now := time.Now()
+	prices := make(map[string]SymbolPrice)
+	for _, symbol := range symbols {
+		if price, ok := filePrices[symbol]; ok {
+			prices[symbol] = SymbolPrice{Price: price, UpdatedAt: now}
+		}
+	}
+	return prices, nil
+}
+
+// httpSource fetches the price of each symbol from a json api, the symbols whose prices fail
+// to be fetched are skipped.
+type httpSource struct {
+	name          string
+	url           string
+	pricePath     string
+	timestampPath string
+	headers       map[string]string
+}
+
+func (s *httpSource) Name() string {
+	return s.name
+}
+
+func (s *httpSource) GetPrices(ctx context.Context, symbols []string) (map[string]SymbolPrice, error) {
+	prices := make(map[string]SymbolPrice)
+	for _, symbol := range symbols {
+		price, ok, err := s.getPrice(ctx, symbol)
+		if err != nil {
+			logx.Errorf("failed to get price of %s from %s, err: %v", symbol, s.name, err)
+			continue
+		}
+		if ok {
+			prices[symbol] = price
+		}
+	}
+	return prices, nil
+}
+
+func (s *httpSource) getPrice(ctx context.Context, symbol string) (SymbolPrice, bool, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", strings.ReplaceAll(s.url, "{symbol}", symbol), nil)
+	if err != nil {
+		return SymbolPrice{}, false, types.HttpErrFailToRequest
+	}
+	request.Header.Add("Accept", "application/json")
+	for key, value := range s.headers {
+		request.Header.Add(key, value)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return SymbolPrice{}, false, types.HttpErrClientDo
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return SymbolPrice{}, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return SymbolPrice{}, false, fmt.Errorf("failed to get price of %s, status: %d", symbol, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SymbolPrice{}, false, types.IoErrFailToRead
+	}
+	var data interface{}
+	if err = json.Unmarshal(body, &data); err != nil {
+		return SymbolPrice{}, false, types.JsonErrUnmarshal
+	}
+
+	value, ok := lookupPath(data, s.pricePath)
+	if !ok {
+		return SymbolPrice{}, false, nil
+	}
+	price, err := toFloat(value)
+	if err != nil {
+		return SymbolPrice{}, false, fmt.Errorf("invalid price of %s: %v", symbol, value)
+	}
+	updatedAt := time.Now()
+	if s.timestampPath != "" {
+		value, ok := lookupPath(data, s.timestampPath)
+		if !ok {
+			return SymbolPrice{}, false, fmt.Errorf("no update time of %s", symbol)
+		}
+		updatedAt, err = toTime(value)
+		if err != nil {
+			return SymbolPrice{}, false, fmt.Errorf("invalid update time of %s: %v", symbol, value)
+		}
+	}
+	return SymbolPrice{Price: price, UpdatedAt: updatedAt}, true, nil
+}
+
+// lookupPath walks a dot separated path of object keys and array indexes in json data.
+func lookupPath(data interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := data.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			data = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			data = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return data, data != nil
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0), nil
+	case string:
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(seconds, 0), nil
+		}
+		return time.Parse(time.RFC3339, v)
+	}
+	return time.Time{}, fmt.Errorf("not a time")
+}
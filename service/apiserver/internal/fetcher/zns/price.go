@@ -0,0 +1,87 @@
+package zns
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/zkbnb/common/rpcpool"
+	"github.com/bnb-chain/zkbnb/dao/sysconfig"
+)
+
+// priceOracleABI is the price function of the ZnsPriceOracle contract.
+const priceOracleABI = `[{"inputs":[{"internalType":"string","name":"name","type":"string"}],"name":"price",` +
+	`"outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+type Config struct {
+	// The name of the sys config with the l1 rpc endpoints to call the price oracle, the
+	// prices of names are not returned if it is empty.
+	//nolint:staticcheck
+	NetworkRPCSysConfigName string `json:",optional"`
+	//nolint:staticcheck
+	RPCPool rpcpool.Config `json:",optional"`
+}
+
+type PriceFetcher interface {
+	// GetPrice returns the price in BNB wei of registering the name without the suffix.
+	GetPrice(ctx context.Context, oracleAddress, name string) (*big.Int, error)
+	Stop()
+}
+
+func NewPriceFetcher(config Config, sysConfigModel sysconfig.SysConfigModel) (PriceFetcher, error) {
+	l1RPCEndpoint, err := sysConfigModel.GetSysConfigByName(config.NetworkRPCSysConfigName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get l1 rpc endpoint, err: %v, name: %s", err, config.NetworkRPCSysConfigName)
+	}
+	rpcPool, err := rpcpool.NewPool(rpcpool.ParseEndpoints(l1RPCEndpoint.Value), config.RPCPool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rpc pool, err: %v", err)
+	}
+	oracleABI, err := abi.JSON(strings.NewReader(priceOracleABI))
+	if err != nil {
+		return nil, err
+	}
+	return &priceFetcher{rpcPool: rpcPool, oracleABI: oracleABI}, nil
+}
+
+type priceFetcher struct {
+	rpcPool   *rpcpool.Pool
+	oracleABI abi.ABI
+}
+
+func (f *priceFetcher) GetPrice(ctx context.Context, oracleAddress, name string) (*big.Int, error) {
+	return getPrice(ctx, f.rpcPool.Client(), f.oracleABI, oracleAddress, name)
+}
+
+func (f *priceFetcher) Stop() {
+	f.rpcPool.Stop()
+}
+
+func getPrice(ctx context.Context, caller ethereum.ContractCaller, oracleABI abi.ABI, oracleAddress, name string) (*big.Int, error) {
+	if !common.IsHexAddress(oracleAddress) {
+		return nil, fmt.Errorf("invalid zns price oracle address: %s", oracleAddress)
+	}
+	input, err := oracleABI.Pack("price", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack price call, err: %v", err)
+	}
+	to := common.HexToAddress(oracleAddress)
+	output, err := caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: input}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call zns price oracle, err: %v", err)
+	}
+	values, err := oracleABI.Unpack("price", output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack zns price, err: %v", err)
+	}
+	price, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("invalid zns price: %v", values[0])
+	}
+	return price, nil
+}
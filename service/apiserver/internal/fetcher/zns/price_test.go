@@ -0,0 +1,52 @@
+package zns
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lengthPriceOracle prices names by their lengths like the zns price oracle.
+type lengthPriceOracle struct {
+	oracleABI abi.ABI
+}
+
+func (o *lengthPriceOracle) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (o *lengthPriceOracle) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	args, err := o.oracleABI.Methods["price"].Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	price := big.NewInt(1e18)
+	if len(args[0].(string)) < 5 {
+		price.Mul(price, big.NewInt(10))
+	}
+	return o.oracleABI.Methods["price"].Outputs.Pack(price)
+}
+
+func TestGetPrice(t *testing.T) {
+	oracleABI, err := abi.JSON(strings.NewReader(priceOracleABI))
+	require.NoError(t, err)
+	oracle := &lengthPriceOracle{oracleABI: oracleABI}
+	address := "0x0000000000000000000000000000000000000001"
+
+	price, err := getPrice(context.Background(), oracle, oracleABI, address, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "1000000000000000000", price.String())
+	price, err = getPrice(context.Background(), oracle, oracleABI, address, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000000000000", price.String())
+
+	_, err = getPrice(context.Background(), oracle, oracleABI, "not an address", "bob")
+	assert.Error(t, err)
+}
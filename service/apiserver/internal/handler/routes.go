@@ -11,6 +11,7 @@ import (
 	nft "github.com/bnb-chain/zkbnb/service/apiserver/internal/handler/nft"
 	root "github.com/bnb-chain/zkbnb/service/apiserver/internal/handler/root"
 	transaction "github.com/bnb-chain/zkbnb/service/apiserver/internal/handler/transaction"
+	zns "github.com/bnb-chain/zkbnb/service/apiserver/internal/handler/zns"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 
 	"github.com/zeromicro/go-zero/rest"
@@ -42,6 +43,26 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		},
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/zns/resolve",
+				Handler: zns.ResolveZnsNameHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/zns/names",
+				Handler: zns.GetZnsNamesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/zns/availability",
+				Handler: zns.GetZnsAvailabilityHandler(serverCtx),
+			},
+		},
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -54,6 +75,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/asset",
 				Handler: asset.GetAssetHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/prices",
+				Handler: asset.GetPricesHandler(serverCtx),
+			},
 		},
 	)
 
@@ -154,6 +180,16 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/sendTx",
 				Handler: transaction.SendTxHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/withdrawals",
+				Handler: transaction.GetWithdrawalsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/priorityRequests",
+				Handler: transaction.GetPriorityRequestsHandler(serverCtx),
+			},
 		},
 	)
 
@@ -169,6 +205,21 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/accountNfts",
 				Handler: nft.GetAccountNftsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/collections",
+				Handler: nft.GetCollectionsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/collection",
+				Handler: nft.GetCollectionHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/collectionNfts",
+				Handler: nft.GetCollectionNftsHandler(serverCtx),
+			},
 		},
 	)
 }
@@ -0,0 +1,52 @@
+package asset
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+type GetPricesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetPricesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPricesLogic {
+	return &GetPricesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetPricesLogic) GetPrices() (*types.Prices, error) {
+	prices := l.svcCtx.PriceFetcher.GetPrices()
+	resp := &types.Prices{
+		Prices: make([]*types.Price, 0, len(prices)),
+	}
+	for _, price := range prices {
+		p := &types.Price{
+			Symbol:  price.Symbol,
+			Price:   strconv.FormatFloat(price.Price, 'E', -1, 64),
+			Sources: make([]*types.SourcePrice, 0, len(price.Sources)),
+		}
+		if !price.UpdatedAt.IsZero() {
+			p.UpdatedAt = price.UpdatedAt.UnixMilli()
+		}
+		for _, source := range price.Sources {
+			p.Sources = append(p.Sources, &types.SourcePrice{
+				Source:    source.Source,
+				Price:     strconv.FormatFloat(source.Price, 'E', -1, 64),
+				UpdatedAt: source.UpdatedAt.UnixMilli(),
+				Stale:     source.Stale,
+			})
+		}
+		resp.Prices = append(resp.Prices, p)
+	}
+	return resp, nil
+}
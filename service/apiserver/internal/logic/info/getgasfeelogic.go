@@ -7,6 +7,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/gasfee"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
 	types2 "github.com/bnb-chain/zkbnb/types"
@@ -54,5 +55,31 @@ func (l *GetGasFeeLogic) GetGasFee(req *types.ReqGetGasFee) (*types.GasFee, erro
 	resp := &types.GasFee{
 		GasFee: strconv.FormatInt(gasFee, 10),
 	}
+	l.fillSource(resp, req)
 	return resp, nil
 }
+
+// fillSource adds the data the fee is derived from if the fees are updated by the oracle.
+func (l *GetGasFeeLogic) fillSource(resp *types.GasFee, req *types.ReqGetGasFee) {
+	sourceConfig, err := l.svcCtx.MemCache.GetSysConfigWithFallback(types2.SysGasFeeSource, func() (interface{}, error) {
+		return l.svcCtx.SysConfigModel.GetSysConfigByName(types2.SysGasFeeSource)
+	})
+	if err != nil {
+		if err != types2.DbErrNotFound {
+			logx.Errorf("fail to get gas fee source, err: %s", err.Error())
+		}
+		return
+	}
+	source := &gasfee.Source{}
+	err = json.Unmarshal([]byte(sourceConfig.Value), source)
+	if err != nil {
+		logx.Errorf("fail to unmarshal gas fee source, err: %s", err.Error())
+		return
+	}
+	resp.L1GasPrice = source.L1GasPrice
+	resp.GasPerTx = source.GasPerTx
+	resp.PubDataSize = source.PubDataSize[int(req.TxType)]
+	resp.AssetPrice = source.Prices[req.AssetId]
+	resp.BnbPrice = source.Prices[types2.BNBAssetId]
+	resp.UpdatedAt = source.UpdatedAt
+}
@@ -0,0 +1,20 @@
+package nft
+
+import (
+	"github.com/bnb-chain/zkbnb/dao/collection"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func convertCollection(svcCtx *svc.ServiceContext, c *collection.Collection) *types.Collection {
+	creatorName, _ := svcCtx.MemCache.GetAccountNameByIndex(c.CreatorAccountIndex)
+	return &types.Collection{
+		CreatorAccountIndex: c.CreatorAccountIndex,
+		CreatorAccountName:  creatorName,
+		CollectionId:        c.CollectionId,
+		Name:                c.Name,
+		Introduction:        c.Introduction,
+		NftCount:            c.NftCount,
+		L2BlockHeight:       c.L2BlockHeight,
+	}
+}
@@ -0,0 +1,43 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type GetCollectionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCollectionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCollectionLogic {
+	return &GetCollectionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCollectionLogic) GetCollection(req *types.ReqGetCollection) (resp *types.Collection, err error) {
+	if req.CreatorAccountIndex < 0 {
+		return nil, types2.AppErrInvalidAccountIndex
+	}
+	if req.CollectionId < 0 {
+		return nil, types2.AppErrInvalidCollectionId
+	}
+
+	c, err := l.svcCtx.CollectionModel.GetCollection(req.CreatorAccountIndex, req.CollectionId)
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, types2.AppErrCollectionNotFound
+		}
+		return nil, types2.AppErrInternal
+	}
+	return convertCollection(l.svcCtx, c), nil
+}
@@ -0,0 +1,50 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type GetCollectionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCollectionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCollectionsLogic {
+	return &GetCollectionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCollectionsLogic) GetCollections(req *types.ReqGetRange) (resp *types.Collections, err error) {
+	total, err := l.svcCtx.CollectionModel.GetCollectionsTotalCount()
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+
+	resp = &types.Collections{
+		Collections: make([]*types.Collection, 0, req.Limit),
+		Total:       uint32(total),
+	}
+
+	if total == 0 || total <= int64(req.Offset) {
+		return resp, nil
+	}
+
+	collections, err := l.svcCtx.CollectionModel.GetCollections(int(req.Limit), int64(req.Offset))
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+	for _, c := range collections {
+		resp.Collections = append(resp.Collections, convertCollection(l.svcCtx, c))
+	}
+	return resp, nil
+}
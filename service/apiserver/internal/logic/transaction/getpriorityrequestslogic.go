@@ -0,0 +1,144 @@
+package transaction
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	queryByL1TxHash      = "l1_tx_hash"
+	queryBySenderAddress = "sender_address"
+	queryByRequestId     = "request_id"
+
+	PriorityRequestStatusPending = "pending"
+	PriorityRequestStatusHandled = "handled"
+)
+
+type GetPriorityRequestsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetPriorityRequestsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPriorityRequestsLogic {
+	return &GetPriorityRequestsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetPriorityRequestsLogic) GetPriorityRequests(req *types.ReqGetPriorityRequests) (resp *types.PriorityRequests, err error) {
+	resp = &types.PriorityRequests{
+		PriorityRequests: make([]*types.PriorityRequest, 0),
+	}
+
+	var requests []*priorityrequest.PriorityRequest
+	switch req.By {
+	case queryByL1TxHash:
+		if len(common.FromHex(req.Value)) != common.HashLength {
+			return nil, types2.AppErrInvalidParam.RefineError("invalid l1 tx hash")
+		}
+		requests, err = l.svcCtx.PriorityRequestModel.GetPriorityRequestsByL1TxHash(common.HexToHash(req.Value).Hex())
+		resp.Total = uint32(len(requests))
+		requests = pagePriorityRequests(requests, req.Offset, req.Limit)
+	case queryBySenderAddress:
+		if !common.IsHexAddress(req.Value) {
+			return nil, types2.AppErrInvalidParam.RefineError("invalid sender address")
+		}
+		senderAddress := common.HexToAddress(req.Value).Hex()
+		total, err := l.svcCtx.PriorityRequestModel.GetPriorityRequestsCountBySender(senderAddress)
+		if err != nil {
+			return nil, types2.AppErrInternal
+		}
+		resp.Total = uint32(total)
+		if total == 0 || total <= int64(req.Offset) {
+			return resp, nil
+		}
+		requests, err = l.svcCtx.PriorityRequestModel.GetPriorityRequestsBySender(senderAddress, int64(req.Limit), int64(req.Offset))
+		if err != nil {
+			return nil, types2.AppErrInternal
+		}
+	case queryByRequestId:
+		var requestId int64
+		requestId, err = strconv.ParseInt(req.Value, 10, 64)
+		if err != nil || requestId < 0 {
+			return nil, types2.AppErrInvalidParam.RefineError("invalid request id")
+		}
+		requests, err = l.svcCtx.PriorityRequestModel.GetPriorityRequestsByRequestIds([]int64{requestId})
+		resp.Total = uint32(len(requests))
+		requests = pagePriorityRequests(requests, req.Offset, req.Limit)
+	default:
+		return nil, types2.AppErrInvalidParam.RefineError("param by should be l1_tx_hash|sender_address|request_id")
+	}
+
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
+		return nil, types2.AppErrInternal
+	}
+
+	for _, request := range requests {
+		priorityRequest, err := l.convertPriorityRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		resp.PriorityRequests = append(resp.PriorityRequests, priorityRequest)
+	}
+	return resp, nil
+}
+
+// convertPriorityRequest links a priority request with the l2 tx created for it, which is
+// in the tx pool until it is packed into a block.
+func (l *GetPriorityRequestsLogic) convertPriorityRequest(request *priorityrequest.PriorityRequest) (*types.PriorityRequest, error) {
+	priorityRequest := &types.PriorityRequest{
+		RequestId:       request.RequestId,
+		L1TxHash:        request.L1TxHash,
+		L1BlockHeight:   request.L1BlockHeight,
+		SenderAddress:   request.SenderAddress,
+		TxType:          request.TxType,
+		Pubdata:         request.Pubdata,
+		ExpirationBlock: request.ExpirationBlock,
+		Status:          PriorityRequestStatusPending,
+		L2TxHash:        request.L2TxHash,
+		CreatedAt:       request.CreatedAt.Unix(),
+	}
+	if request.Status != priorityrequest.HandledStatus || request.L2TxHash == "" {
+		return priorityRequest, nil
+	}
+	priorityRequest.Status = PriorityRequestStatusHandled
+
+	tx, err := l.svcCtx.TxModel.GetTxByHash(request.L2TxHash)
+	if err == types2.DbErrNotFound {
+		tx, err = l.svcCtx.TxPoolModel.GetTxByTxHash(request.L2TxHash)
+	}
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return priorityRequest, nil
+		}
+		return nil, types2.AppErrInternal
+	}
+	priorityRequest.L2TxStatus = int64(tx.TxStatus)
+	priorityRequest.L2BlockHeight = tx.BlockHeight
+	return priorityRequest, nil
+}
+
+func pagePriorityRequests(requests []*priorityrequest.PriorityRequest, offset, limit uint16) []*priorityrequest.PriorityRequest {
+	if int(offset) >= len(requests) {
+		return nil
+	}
+	end := int(offset) + int(limit)
+	if end > len(requests) {
+		end = len(requests)
+	}
+	return requests[offset:end]
+}
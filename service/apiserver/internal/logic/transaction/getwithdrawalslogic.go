@@ -0,0 +1,148 @@
+package transaction
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/dao/withdrawal"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	WithdrawalStatusExecutedOnL2   = "executed_on_l2"
+	WithdrawalStatusCommittedOnL1  = "committed_on_l1"
+	WithdrawalStatusVerifiedOnL1   = "verified_on_l1"
+	WithdrawalStatusPendingBalance = "pending_balance"
+	WithdrawalStatusClaimed        = "claimed"
+)
+
+var withdrawalTxTypes = []int64{
+	types2.TxTypeWithdraw,
+	types2.TxTypeWithdrawNft,
+	types2.TxTypeFullExit,
+	types2.TxTypeFullExitNft,
+}
+
+type GetWithdrawalsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetWithdrawalsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetWithdrawalsLogic {
+	return &GetWithdrawalsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetWithdrawalsLogic) GetWithdrawals(req *types.ReqGetWithdrawals) (resp *types.Withdrawals, err error) {
+	resp = &types.Withdrawals{
+		Withdrawals: make([]*types.Withdrawal, 0, req.Limit),
+	}
+
+	accountIndex := int64(0)
+	switch req.By {
+	case queryByAccountIndex:
+		accountIndex, err = strconv.ParseInt(req.Value, 10, 64)
+		if err != nil || accountIndex < 0 {
+			return nil, types2.AppErrInvalidAccountIndex
+		}
+	case queryByAccountName:
+		accountIndex, err = l.svcCtx.MemCache.GetAccountIndexByName(req.Value)
+	case queryByAccountPk:
+		accountIndex, err = l.svcCtx.MemCache.GetAccountIndexByPk(req.Value)
+	default:
+		return nil, types2.AppErrInvalidParam.RefineError("param by should be account_index|account_name|account_pk")
+	}
+
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
+		return nil, types2.AppErrInternal
+	}
+
+	total, err := l.svcCtx.TxModel.GetTxsCountByAccountIndex(accountIndex, tx.GetTxWithTypes(withdrawalTxTypes))
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+
+	resp.Total = uint32(total)
+	if total == 0 || total <= int64(req.Offset) {
+		return resp, nil
+	}
+
+	txs, err := l.svcCtx.TxModel.GetTxsByAccountIndex(accountIndex, int64(req.Limit), int64(req.Offset),
+		tx.GetTxWithTypes(withdrawalTxTypes))
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+
+	txHashes := make([]string, 0, len(txs))
+	for _, dbTx := range txs {
+		txHashes = append(txHashes, dbTx.TxHash)
+	}
+	withdrawals, err := l.svcCtx.WithdrawalModel.GetWithdrawalsByL2TxHashes(txHashes)
+	if err != nil && err != types2.DbErrNotFound {
+		return nil, types2.AppErrInternal
+	}
+	withdrawalMap := make(map[string]*withdrawal.Withdrawal, len(withdrawals))
+	for _, w := range withdrawals {
+		withdrawalMap[w.L2TxHash] = w
+	}
+
+	for _, dbTx := range txs {
+		w := &types.Withdrawal{
+			L2TxHash:      dbTx.TxHash,
+			TxType:        dbTx.TxType,
+			AccountIndex:  dbTx.AccountIndex,
+			AssetId:       dbTx.AssetId,
+			AssetAmount:   dbTx.TxAmount,
+			NftIndex:      dbTx.NftIndex,
+			L2BlockHeight: dbTx.BlockHeight,
+			Status:        withdrawalStatus(dbTx, withdrawalMap[dbTx.TxHash]),
+			CreatedAt:     dbTx.CreatedAt.Unix(),
+		}
+		w.AccountName, _ = l.svcCtx.MemCache.GetAccountNameByIndex(w.AccountIndex)
+		if dbTx.TxType == types2.TxTypeWithdraw || dbTx.TxType == types2.TxTypeFullExit {
+			w.AssetName, _ = l.svcCtx.MemCache.GetAssetNameById(w.AssetId)
+		}
+		if record, ok := withdrawalMap[dbTx.TxHash]; ok {
+			w.ToAddress = record.ToAddress
+			w.L1TxHash = record.L1TxHash
+			w.ClaimTxHash = record.ClaimTxHash
+		}
+		resp.Withdrawals = append(resp.Withdrawals, w)
+	}
+	return resp, nil
+}
+
+// withdrawalStatus follows the tx status until the block is executed on l1, after which the
+// withdrawal events recorded by the monitor tell whether the assets are sent or pending.
+func withdrawalStatus(dbTx *tx.Tx, record *withdrawal.Withdrawal) string {
+	if record != nil {
+		switch record.Status {
+		case withdrawal.StatusPendingBalance:
+			return WithdrawalStatusPendingBalance
+		case withdrawal.StatusClaimed:
+			return WithdrawalStatusClaimed
+		default:
+			return WithdrawalStatusVerifiedOnL1
+		}
+	}
+	switch dbTx.TxStatus {
+	case tx.StatusVerified:
+		return WithdrawalStatusVerifiedOnL1
+	case tx.StatusCommitted:
+		return WithdrawalStatusCommittedOnL1
+	default:
+		return WithdrawalStatusExecutedOnL2
+	}
+}
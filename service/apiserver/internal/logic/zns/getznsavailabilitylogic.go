@@ -0,0 +1,61 @@
+package zns
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type GetZnsAvailabilityLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetZnsAvailabilityLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetZnsAvailabilityLogic {
+	return &GetZnsAvailabilityLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetZnsAvailabilityLogic) GetZnsAvailability(req *types.ReqGetZnsName) (resp *types.ZnsAvailability, err error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.ZnsAvailability{Name: name}
+	_, err = l.svcCtx.MemCache.GetAccountIndexByName(name)
+	if err == nil {
+		return resp, nil
+	}
+	if err != types2.DbErrNotFound {
+		return nil, types2.AppErrInternal
+	}
+	resp.Available = true
+
+	// The price is only known if the l1 rpc of the price oracle is configured.
+	if l.svcCtx.ZnsPriceFetcher == nil {
+		return resp, nil
+	}
+	oracleConfig, err := l.svcCtx.MemCache.GetSysConfigWithFallback(types2.ZnsPriceOracle, func() (interface{}, error) {
+		return l.svcCtx.SysConfigModel.GetSysConfigByName(types2.ZnsPriceOracle)
+	})
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+	price, err := l.svcCtx.ZnsPriceFetcher.GetPrice(l.ctx, oracleConfig.Value, strings.TrimSuffix(name, types2.AccountNameSuffix))
+	if err != nil {
+		logx.Errorf("failed to get price of zns name %s, err: %v", name, err)
+		return nil, types2.AppErrInternal
+	}
+	resp.Price = price.String()
+	return resp, nil
+}
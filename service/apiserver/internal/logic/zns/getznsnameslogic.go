@@ -0,0 +1,63 @@
+package zns
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type GetZnsNamesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetZnsNamesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetZnsNamesLogic {
+	return &GetZnsNamesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetZnsNamesLogic) GetZnsNames(req *types.ReqGetZnsNames) (resp *types.ZnsNames, err error) {
+	if !common.IsHexAddress(req.L1Address) {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid l1 address")
+	}
+
+	resp = &types.ZnsNames{
+		Names: make([]*types.ZnsName, 0, req.Limit),
+	}
+	total, err := l.svcCtx.AccountModel.GetAccountsCountByL1Address(req.L1Address)
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+
+	resp.Total = uint32(total)
+	if total == 0 || total <= int64(req.Offset) {
+		return resp, nil
+	}
+
+	accounts, err := l.svcCtx.AccountModel.GetAccountsByL1Address(req.L1Address, int(req.Limit), int64(req.Offset))
+	if err != nil {
+		if err != types2.DbErrNotFound {
+			return nil, types2.AppErrInternal
+		}
+		return resp, nil
+	}
+
+	for _, account := range accounts {
+		resp.Names = append(resp.Names, &types.ZnsName{
+			Name:      account.AccountName,
+			Index:     account.AccountIndex,
+			L1Address: account.L1Address,
+			Pk:        account.PublicKey,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,26 @@
+package zns
+
+import (
+	"strings"
+
+	"github.com/bnb-chain/zkbnb/common"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+// maxNameLength is the max length of a zns name without the suffix allowed by the contract.
+const maxNameLength = 20
+
+// normalizeName cleans up a zns name given with or without the suffix, and returns it with
+// the suffix like the names of the accounts.
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSuffix(common.CleanAccountName(name), types2.AccountNameSuffix)
+	if len(name) == 0 || len(name) > maxNameLength {
+		return "", types2.AppErrInvalidAccountName
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return "", types2.AppErrInvalidAccountName
+		}
+	}
+	return name + types2.AccountNameSuffix, nil
+}
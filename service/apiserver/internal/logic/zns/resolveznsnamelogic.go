@@ -0,0 +1,57 @@
+package zns
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type ResolveZnsNameLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResolveZnsNameLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResolveZnsNameLogic {
+	return &ResolveZnsNameLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResolveZnsNameLogic) ResolveZnsName(req *types.ReqGetZnsName) (resp *types.ZnsName, err error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := l.svcCtx.MemCache.GetAccountIndexByName(name)
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, types2.AppErrAccountNotFound
+		}
+		return nil, types2.AppErrInternal
+	}
+	account, err := l.svcCtx.MemCache.GetAccountWithFallback(index, func() (interface{}, error) {
+		return l.svcCtx.AccountModel.GetAccountByIndex(index)
+	})
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, types2.AppErrAccountNotFound
+		}
+		return nil, types2.AppErrInternal
+	}
+
+	resp = &types.ZnsName{
+		Name:      account.AccountName,
+		Index:     account.AccountIndex,
+		L1Address: account.L1Address,
+		Pk:        account.PublicKey,
+	}
+	return resp, nil
+}
@@ -10,14 +10,19 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/account"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
 	"github.com/bnb-chain/zkbnb/dao/dbcache"
 	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/dao/withdrawal"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/config"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/gasfee"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/price"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/state"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/zns"
 )
 
 type ServiceContext struct {
@@ -25,18 +30,24 @@ type ServiceContext struct {
 	RedisCache dbcache.Cache
 	MemCache   *cache.MemCache
 
-	DB                  *gorm.DB
-	TxPoolModel         tx.TxPoolModel
-	AccountModel        account.AccountModel
-	AccountHistoryModel account.AccountHistoryModel
-	TxModel             tx.TxModel
-	BlockModel          block.BlockModel
-	NftModel            nft.L2NftModel
-	AssetModel          asset.AssetModel
-	SysConfigModel      sysconfig.SysConfigModel
+	DB                   *gorm.DB
+	TxPoolModel          tx.TxPoolModel
+	AccountModel         account.AccountModel
+	AccountHistoryModel  account.AccountHistoryModel
+	TxModel              tx.TxModel
+	BlockModel           block.BlockModel
+	NftModel             nft.L2NftModel
+	CollectionModel      collection.CollectionModel
+	AssetModel           asset.AssetModel
+	SysConfigModel       sysconfig.SysConfigModel
+	WithdrawalModel      withdrawal.WithdrawalModel
+	PriorityRequestModel priorityrequest.PriorityRequestModel
 
 	PriceFetcher price.Fetcher
 	StateFetcher state.Fetcher
+	GasFeeOracle gasfee.Oracle
+	// Nil if no l1 rpc is configured for the zns price oracle.
+	ZnsPriceFetcher zns.PriceFetcher
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -59,23 +70,47 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	nftModel := nft.NewL2NftModel(db)
 	assetModel := asset.NewAssetModel(db)
 	memCache := cache.MustNewMemCache(accountModel, assetModel, c.MemCache.AccountExpiration, c.MemCache.BlockExpiration,
-		c.MemCache.TxExpiration, c.MemCache.AssetExpiration, c.MemCache.PriceExpiration, c.MemCache.MaxCounterNum, c.MemCache.MaxKeyNum)
+		c.MemCache.TxExpiration, c.MemCache.AssetExpiration, c.MemCache.MaxCounterNum, c.MemCache.MaxKeyNum)
+	sysConfigModel := sysconfig.NewSysConfigModel(db)
+	priceFetcher, err := price.NewFetcher(assetModel, c.PriceFeed, c.CoinMarketCap.Url, c.CoinMarketCap.Token)
+	if err != nil {
+		logx.Must(err)
+	}
+	var gasFeeOracle gasfee.Oracle
+	if c.GasFeeOracle.Enabled {
+		gasFeeOracle, err = gasfee.NewOracle(c.GasFeeOracle, db, sysConfigModel, assetModel, priceFetcher, redisCache, memCache)
+		if err != nil {
+			logx.Must(err)
+		}
+	}
+	var znsPriceFetcher zns.PriceFetcher
+	if c.Zns.NetworkRPCSysConfigName != "" {
+		znsPriceFetcher, err = zns.NewPriceFetcher(c.Zns, sysConfigModel)
+		if err != nil {
+			logx.Must(err)
+		}
+	}
 	return &ServiceContext{
-		Config:              c,
-		RedisCache:          redisCache,
-		MemCache:            memCache,
-		DB:                  db,
-		TxPoolModel:         txPoolModel,
-		AccountModel:        accountModel,
-		AccountHistoryModel: account.NewAccountHistoryModel(db),
-		TxModel:             tx.NewTxModel(db),
-		BlockModel:          block.NewBlockModel(db),
-		NftModel:            nftModel,
-		AssetModel:          assetModel,
-		SysConfigModel:      sysconfig.NewSysConfigModel(db),
+		Config:               c,
+		RedisCache:           redisCache,
+		MemCache:             memCache,
+		DB:                   db,
+		TxPoolModel:          txPoolModel,
+		AccountModel:         accountModel,
+		AccountHistoryModel:  account.NewAccountHistoryModel(db),
+		TxModel:              tx.NewTxModel(db),
+		BlockModel:           block.NewBlockModel(db),
+		NftModel:             nftModel,
+		CollectionModel:      collection.NewCollectionModel(db),
+		AssetModel:           assetModel,
+		SysConfigModel:       sysConfigModel,
+		WithdrawalModel:      withdrawal.NewWithdrawalModel(db),
+		PriorityRequestModel: priorityrequest.NewPriorityRequestModel(db),
 
-		PriceFetcher: price.NewFetcher(memCache, assetModel, c.CoinMarketCap.Url, c.CoinMarketCap.Token),
-		StateFetcher: state.NewFetcher(redisCache, accountModel, nftModel),
+		PriceFetcher:    priceFetcher,
+		StateFetcher:    state.NewFetcher(redisCache, accountModel, nftModel),
+		GasFeeOracle:    gasFeeOracle,
+		ZnsPriceFetcher: znsPriceFetcher,
 	}
 }
 
@@ -86,4 +121,10 @@ func (s *ServiceContext) Shutdown() {
 	}
 	_ = s.RedisCache.Close()
 	s.PriceFetcher.Stop()
+	if s.GasFeeOracle != nil {
+		s.GasFeeOracle.Stop()
+	}
+	if s.ZnsPriceFetcher != nil {
+		s.ZnsPriceFetcher.Stop()
+	}
 }
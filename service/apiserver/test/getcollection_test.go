@@ -0,0 +1,71 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetCollection() {
+	type args struct {
+		creatorAccountIndex int64
+		collectionId        int64
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found", args{math.MaxInt32, 0}, 400},
+		{"invalid collection id", args{0, -1}, 400},
+	}
+
+	statusCode, collections := GetCollections(s, 0, 100)
+	if statusCode == http.StatusOK && len(collections.Collections) > 0 {
+		c := collections.Collections[0]
+		tests = append(tests, []testcase{
+			{"found", args{c.CreatorAccountIndex, c.CollectionId}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetCollection(s, tt.args.creatorAccountIndex, tt.args.collectionId)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args.creatorAccountIndex, result.CreatorAccountIndex)
+				assert.Equal(t, tt.args.collectionId, result.CollectionId)
+				assert.True(t, result.NftCount >= 0)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetCollection(s *ApiServerSuite, creatorAccountIndex, collectionId int64) (int, *types.Collection) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/collection?creator_account_index=%d&collection_id=%d", s.url, creatorAccountIndex, collectionId))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Collection{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetCollectionNfts() {
+	type args struct {
+		creatorAccountIndex int64
+		collectionId        int64
+		offset              int
+		limit               int
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"no nfts", args{math.MaxInt32, 0, 0, 10}, 200},
+		{"invalid limit", args{0, 0, 0, 0}, 400},
+	}
+
+	statusCode, collections := GetCollections(s, 0, 100)
+	if statusCode == http.StatusOK && len(collections.Collections) > 0 {
+		c := collections.Collections[0]
+		tests = append(tests, []testcase{
+			{"found", args{c.CreatorAccountIndex, c.CollectionId, 0, 10}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetCollectionNfts(s, tt.args.creatorAccountIndex, tt.args.collectionId, tt.args.offset, tt.args.limit)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.True(t, len(result.Nfts) <= tt.args.limit)
+				for _, nft := range result.Nfts {
+					assert.Equal(t, tt.args.creatorAccountIndex, nft.CreatorAccountIndex)
+					assert.Equal(t, tt.args.collectionId, nft.CollectionId)
+				}
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetCollectionNfts(s *ApiServerSuite, creatorAccountIndex, collectionId int64, offset, limit int) (int, *types.Nfts) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/collectionNfts?creator_account_index=%d&collection_id=%d&offset=%d&limit=%d",
+		s.url, creatorAccountIndex, collectionId, offset, limit))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Nfts{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetCollections() {
+	type args struct {
+		offset int
+		limit  int
+	}
+	tests := []struct {
+		name     string
+		args     args
+		httpCode int
+	}{
+		{"found", args{0, 10}, 200},
+		{"invalid limit", args{0, 0}, 400},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetCollections(s, tt.args.offset, tt.args.limit)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.NotNil(t, result.Collections)
+				assert.True(t, len(result.Collections) <= tt.args.limit)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetCollections(s *ApiServerSuite, offset, limit int) (int, *types.Collections) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/collections?offset=%d&limit=%d", s.url, offset, limit))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Collections{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+func (s *ApiServerSuite) TestGetGasFee() {
+	type args struct {
+		assetId int
+		txType  int
+	}
+	tests := []struct {
+		name     string
+		args     args
+		httpCode int
+	}{
+		{"found", args{types2.BNBAssetId, types2.TxTypeTransfer}, 200},
+		{"invalid asset", args{99999, types2.TxTypeTransfer}, 400},
+		{"invalid tx type", args{types2.BNBAssetId, types2.TxTypeDeposit}, 400},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetGasFee(s, tt.args.assetId, tt.args.txType)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.NotEmpty(t, result.GasFee)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetGasFee(s *ApiServerSuite, assetId, txType int) (int, *types.GasFee) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/gasFee?asset_id=%d&tx_type=%d", s.url, assetId, txType))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.GasFee{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetPriorityRequests() {
+	type args struct {
+		by     string
+		value  string
+		offset int
+		limit  int
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found by l1 tx hash", args{"l1_tx_hash", "0x5a2bc52f6d3fe3a3e1e1b4a4e3e1d9a3d0c1d8d5aef08a0f2e4e8d1a8a3b9c7d", 0, 10}, 200},
+		{"not found by sender address", args{"sender_address", "0x0000000000000000000000000000000000000001", 0, 10}, 200},
+		{"not found by request id", args{"request_id", "99999999", 0, 10}, 200},
+		{"invalid l1 tx hash", args{"l1_tx_hash", "0x5a2b", 0, 10}, 400},
+		{"invalid sender address", args{"sender_address", "fakeaddress", 0, 10}, 400},
+		{"invalid request id", args{"request_id", "-1", 0, 10}, 400},
+		{"invalid by", args{"invalidby", "1", 0, 10}, 400},
+	}
+
+	statusCode, result := GetPriorityRequests(s, "request_id", "0", 0, 10)
+	if statusCode == http.StatusOK && len(result.PriorityRequests) > 0 {
+		request := result.PriorityRequests[0]
+		tests = append(tests, []testcase{
+			{"found by l1 tx hash", args{"l1_tx_hash", request.L1TxHash, 0, 10}, 200},
+			{"found by sender address", args{"sender_address", request.SenderAddress, 0, 10}, 200},
+			{"found by request id", args{"request_id", strconv.Itoa(int(request.RequestId)), 0, 10}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetPriorityRequests(s, tt.args.by, tt.args.value, tt.args.offset, tt.args.limit)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				if tt.args.offset < int(result.Total) {
+					assert.True(t, len(result.PriorityRequests) > 0)
+					assert.NotEmpty(t, result.PriorityRequests[0].L1TxHash)
+					assert.NotEmpty(t, result.PriorityRequests[0].Status)
+				}
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetPriorityRequests(s *ApiServerSuite, by, value string, offset, limit int) (int, *types.PriorityRequests) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/priorityRequests?by=%s&value=%s&offset=%d&limit=%d", s.url, by, value, offset, limit))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.PriorityRequests{}
+	//nolint:errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
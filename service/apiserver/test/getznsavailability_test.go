@@ -0,0 +1,64 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetZnsAvailability() {
+	type testcase struct {
+		name      string
+		args      string // zns name
+		httpCode  int
+		available bool
+	}
+
+	tests := []testcase{
+		{"invalid name", "a_name_longer_than_twenty", 400, false},
+		{"available", "notexistname", 200, true},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 0 {
+		tests = append(tests, []testcase{
+			{"registered", accounts.Accounts[0].Name, 200, false},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetZnsAvailability(s, tt.args)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.available, result.Available)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetZnsAvailability(s *ApiServerSuite, name string) (int, *types.ZnsAvailability) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/zns/availability?name=%s", s.url, url.QueryEscape(name)))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.ZnsAvailability{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
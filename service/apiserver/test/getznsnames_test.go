@@ -0,0 +1,71 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetZnsNames() {
+	type testcase struct {
+		name     string
+		args     string // l1 address
+		httpCode int
+		total    int
+	}
+
+	tests := []testcase{
+		{"invalid address", "0x123", 400, 0},
+		{"no names", "0x0000000000000000000000000000000000000001", 200, 0},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 0 {
+		_, name := ResolveZnsName(s, accounts.Accounts[0].Name)
+		if name != nil {
+			tests = append(tests, []testcase{
+				{"found", name.L1Address, 200, -1},
+			}...)
+		}
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetZnsNames(s, tt.args, 0, 100)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				if tt.total >= 0 {
+					assert.Equal(t, tt.total, int(result.Total))
+				} else {
+					assert.True(t, result.Total > 0)
+				}
+				assert.LessOrEqual(t, len(result.Names), int(result.Total))
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetZnsNames(s *ApiServerSuite, l1Address string, offset, limit int) (int, *types.ZnsNames) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/zns/names?l1_address=%s&offset=%d&limit=%d", s.url, l1Address, offset, limit))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.ZnsNames{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
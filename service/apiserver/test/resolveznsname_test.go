@@ -0,0 +1,65 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestResolveZnsName() {
+	type testcase struct {
+		name     string
+		args     string // zns name
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"invalid name", "not-a-name!", 400},
+		{"not found", "notexistname", 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 0 {
+		tests = append(tests, []testcase{
+			{"found", accounts.Accounts[0].Name, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := ResolveZnsName(s, tt.args)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args, result.Name)
+				assert.NotEmpty(t, result.Pk)
+				assert.NotEmpty(t, result.L1Address)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func ResolveZnsName(s *ApiServerSuite, name string) (int, *types.ZnsName) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/zns/resolve?name=%s", s.url, url.QueryEscape(name)))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.ZnsName{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
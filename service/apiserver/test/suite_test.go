@@ -77,10 +77,9 @@ func (s *ApiServerSuite) SetupSuite() {
 			AssetExpiration   int
 			BlockExpiration   int
 			TxExpiration      int
-			PriceExpiration   int
 			MaxCounterNum     int64
 			MaxKeyNum         int64
-		}{AccountExpiration: 10000, AssetExpiration: 10000, BlockExpiration: 10000, TxExpiration: 10000, PriceExpiration: 3600000, MaxCounterNum: 10000, MaxKeyNum: 10000},
+		}{AccountExpiration: 10000, AssetExpiration: 10000, BlockExpiration: 10000, TxExpiration: 10000, MaxCounterNum: 10000, MaxKeyNum: 10000},
 	}
 	c.Postgres = struct {
 		DataSource string
@@ -11,14 +11,12 @@ import (
 
 	"github.com/bnb-chain/zkbnb/core"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
 	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tree"
 	"github.com/bnb-chain/zkbnb/types"
 )
 
-const (
-	MaxCommitterInterval = 60 * 1
-)
-
 var (
 	priorityOperationMetric = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "zkbnb",
@@ -65,17 +63,14 @@ var (
 type Config struct {
 	core.ChainConfig
 
-	BlockConfig struct {
-		OptionalBlockSizes []int
-	}
-	LogConf logx.LogConf
+	BlockConfig BlockPolicyConfig
+	LogConf     logx.LogConf
 }
 
 type Committer struct {
-	running            bool
-	config             *Config
-	maxTxsPerBlock     int
-	optionalBlockSizes []int
+	running bool
+	config  *Config
+	policy  BlockPolicy
 
 	bc *core.BlockChain
 }
@@ -89,6 +84,20 @@ func NewCommitter(config *Config) (*Committer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("new blockchain error: %v", err)
 	}
+	// add the index of the l1 addresses of the zns names to tables created by former versions
+	err = bc.AccountModel.CreateAccountTable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate account table, err: %v", err)
+	}
+	// the collections created by former versions are filled by the migrate-collection command
+	err = bc.CollectionModel.CreateCollectionTable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate collection table, err: %v", err)
+	}
+	err = bc.L2NftModel.CreateL2NftTable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate l2 nft table, err: %v", err)
+	}
 
 	if err := prometheus.Register(priorityOperationMetric); err != nil {
 		return nil, fmt.Errorf("prometheus.Register priorityOperationMetric error: %v", err)
@@ -114,12 +123,21 @@ func NewCommitter(config *Config) (*Committer, error) {
 	if err := prometheus.Register(sqlDBOperationMetics); err != nil {
 		return nil, fmt.Errorf("prometheus.Register sqlDBOperationMetics error: %v", err)
 	}
+	if err := tree.RegisterAssetTreeCacheMetrics(); err != nil {
+		return nil, err
+	}
+	if config.TreeDB.AssetTreeWarmUpSize > 0 {
+		err = tree.WarmUpAssetTrees(bc.AccountHistoryModel, bc.CurrentBlock().BlockHeight,
+			config.TreeDB.AssetTreeWarmUpSize, bc.Statedb.AccountAssetTrees)
+		if err != nil {
+			return nil, fmt.Errorf("warm up asset trees error: %v", err)
+		}
+	}
 
 	committer := &Committer{
-		running:            true,
-		config:             config,
-		maxTxsPerBlock:     config.BlockConfig.OptionalBlockSizes[len(config.BlockConfig.OptionalBlockSizes)-1],
-		optionalBlockSizes: config.BlockConfig.OptionalBlockSizes,
+		running: true,
+		config:  config,
+		policy:  NewBlockPolicy(config.BlockConfig),
 
 		bc: bc,
 	}
@@ -155,8 +173,9 @@ func (c *Committer) Run() {
 			logx.Error("get pending transactions from tx pool failed:", err)
 			return
 		}
+		pendingRequestCount := c.getPendingPriorityRequestCount()
 		for len(pendingTxs) == 0 {
-			if c.shouldCommit(curBlock) {
+			if c.shouldCommit(curBlock, 0, pendingRequestCount) {
 				break
 			}
 
@@ -166,14 +185,15 @@ func (c *Committer) Run() {
 				logx.Error("get pending transactions from tx pool failed:", err)
 				return
 			}
+			pendingRequestCount = c.getPendingPriorityRequestCount()
 		}
 
 		pendingTxNumMetrics.Set(float64(len(pendingTxs)))
 		pendingUpdatePoolTxs := make([]*tx.Tx, 0, len(pendingTxs))
 		pendingDeletePoolTxs := make([]*tx.Tx, 0, len(pendingTxs))
 		start := time.Now()
-		for _, poolTx := range pendingTxs {
-			if c.shouldCommit(curBlock) {
+		for i, poolTx := range pendingTxs {
+			if c.shouldCommit(curBlock, len(pendingTxs)-i, pendingRequestCount) {
 				break
 			}
 			logx.Infof("apply transaction, txHash=%s", poolTx.TxHash)
@@ -230,7 +250,7 @@ func (c *Committer) Run() {
 			panic("update tx pool failed: " + err.Error())
 		}
 
-		if c.shouldCommit(curBlock) {
+		if c.shouldCommit(curBlock, 0, pendingRequestCount) {
 			start := time.Now()
 			logx.Infof("commit new block, height=%d, blockSize=%d", curBlock.BlockHeight, curBlock.BlockSize)
 			curBlock, err = c.commitNewBlock(curBlock)
@@ -298,18 +318,23 @@ func (c *Committer) createNewBlock(curBlock *block.Block, poolTx *tx.Tx) error {
 	})
 }
 
-func (c *Committer) shouldCommit(curBlock *block.Block) bool {
-	var now = time.Now()
-	if (len(c.bc.Statedb.Txs) > 0 && now.Unix()-curBlock.CreatedAt.Unix() >= MaxCommitterInterval) ||
-		len(c.bc.Statedb.Txs) >= c.maxTxsPerBlock {
-		return true
-	}
+func (c *Committer) shouldCommit(curBlock *block.Block, pendingTxCount int, pendingRequestCount int64) bool {
+	return c.policy.ShouldCommit(curBlock.CreatedAt, c.bc.Statedb.Txs, pendingTxCount, pendingRequestCount, time.Now())
+}
 
-	return false
+// getPendingPriorityRequestCount returns the number of priority requests which are not added
+// to the tx pool yet, they only shorten the commit timeout so a failed query is not fatal.
+func (c *Committer) getPendingPriorityRequestCount() int64 {
+	count, err := c.bc.PriorityRequestModel.GetPriorityRequestCountByStatus(priorityrequest.PendingStatus)
+	if err != nil {
+		logx.Errorf("get pending priority request count failed, err: %v", err)
+		return 0
+	}
+	return count
 }
 
 func (c *Committer) commitNewBlock(curBlock *block.Block) (*block.Block, error) {
-	blockSize := c.computeCurrentBlockSize()
+	blockSize := c.policy.BlockSize(len(c.bc.Statedb.Txs))
 	start := time.Now()
 	blockStates, err := c.bc.CommitNewBlock(blockSize, curBlock.CreatedAt.UnixMilli())
 	if err != nil {
@@ -362,6 +387,19 @@ func (c *Committer) commitNewBlock(curBlock *block.Block) (*block.Block, error)
 				return err
 			}
 		}
+		// create collections and count their nfts
+		if len(blockStates.PendingCollection) != 0 {
+			err = c.bc.DB().CollectionModel.CreateCollectionsInTransact(tx, blockStates.PendingCollection)
+			if err != nil {
+				return err
+			}
+		}
+		if len(blockStates.PendingCollectionNftCount) != 0 {
+			err = c.bc.DB().CollectionModel.UpdateNftCountsInTransact(tx, blockStates.PendingCollectionNftCount)
+			if err != nil {
+				return err
+			}
+		}
 		// delete txs from tx pool
 		err := c.bc.DB().TxPoolModel.DeleteTxsInTransact(tx, blockStates.Block.Txs)
 		if err != nil {
@@ -379,17 +417,6 @@ func (c *Committer) commitNewBlock(curBlock *block.Block) (*block.Block, error)
 	return blockStates.Block, nil
 }
 
-func (c *Committer) computeCurrentBlockSize() int {
-	var blockSize int
-	for i := 0; i < len(c.optionalBlockSizes); i++ {
-		if len(c.bc.Statedb.Txs) <= c.optionalBlockSizes[i] {
-			blockSize = c.optionalBlockSizes[i]
-			break
-		}
-	}
-	return blockSize
-}
-
 func (c *Committer) getLatestExecutedRequestId() (int64, error) {
 
 	statuses := []int{
@@ -0,0 +1,112 @@
+package committer
+
+import (
+	"time"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	MaxCommitterInterval = 60 * 1
+)
+
+// BlockPolicy decides when the proposing block is committed and which of the optional
+// block sizes it is padded to.
+type BlockPolicy interface {
+	// ShouldCommit reports whether the block should be committed with the executed txs,
+	// pendingTxCount is the number of pool txs which are fetched but not executed yet, and
+	// pendingRequestCount is the number of priority requests not yet added to the tx pool.
+	ShouldCommit(createdAt time.Time, executedTxs []*tx.Tx, pendingTxCount int, pendingRequestCount int64, now time.Time) bool
+	// BlockSize returns the block size for the given number of executed txs.
+	BlockSize(txCount int) int
+}
+
+type BlockPolicyConfig struct {
+	OptionalBlockSizes []int
+	// The max seconds a block waits for more txs before it is committed.
+	//nolint:staticcheck
+	CommitTimeout int `json:",optional"`
+	// The max seconds a block containing deposits, full exits or other priority
+	// operations, or followed by pending priority requests, waits before it is
+	// committed, it should not exceed CommitTimeout.
+	//nolint:staticcheck
+	PriorityCommitTimeout int `json:",optional"`
+	// Commit the block once the tx pool is drained and the executed txs fill the
+	// selected block size to this ratio, 0 disables the check.
+	//nolint:staticcheck
+	MinFillRatio float64 `json:",optional"`
+}
+
+type defaultBlockPolicy struct {
+	optionalBlockSizes    []int
+	maxTxsPerBlock        int
+	commitTimeout         time.Duration
+	priorityCommitTimeout time.Duration
+	minFillRatio          float64
+}
+
+func NewBlockPolicy(config BlockPolicyConfig) BlockPolicy {
+	commitTimeout := config.CommitTimeout
+	if commitTimeout <= 0 {
+		commitTimeout = MaxCommitterInterval
+	}
+	priorityCommitTimeout := config.PriorityCommitTimeout
+	if priorityCommitTimeout <= 0 || priorityCommitTimeout > commitTimeout {
+		priorityCommitTimeout = commitTimeout
+	}
+	return &defaultBlockPolicy{
+		optionalBlockSizes:    config.OptionalBlockSizes,
+		maxTxsPerBlock:        config.OptionalBlockSizes[len(config.OptionalBlockSizes)-1],
+		commitTimeout:         time.Duration(commitTimeout) * time.Second,
+		priorityCommitTimeout: time.Duration(priorityCommitTimeout) * time.Second,
+		minFillRatio:          config.MinFillRatio,
+	}
+}
+
+func (p *defaultBlockPolicy) ShouldCommit(createdAt time.Time, executedTxs []*tx.Tx, pendingTxCount int, pendingRequestCount int64, now time.Time) bool {
+	txCount := len(executedTxs)
+	if txCount == 0 {
+		return false
+	}
+	if txCount >= p.maxTxsPerBlock {
+		return true
+	}
+
+	elapsed := now.Sub(createdAt)
+	if elapsed >= p.commitTimeout {
+		return true
+	}
+	// A waiting priority request is only included in the next block, so do not hold it
+	// for the whole commit timeout either.
+	if elapsed >= p.priorityCommitTimeout && (hasPriorityOperation(executedTxs) || pendingRequestCount > 0) {
+		return true
+	}
+
+	// Do not cut the block while there are still txs to execute, they may fill a larger block.
+	if p.minFillRatio > 0 && pendingTxCount == 0 &&
+		float64(txCount) >= p.minFillRatio*float64(p.BlockSize(txCount)) {
+		return true
+	}
+	return false
+}
+
+func (p *defaultBlockPolicy) BlockSize(txCount int) int {
+	var blockSize int
+	for i := 0; i < len(p.optionalBlockSizes); i++ {
+		if txCount <= p.optionalBlockSizes[i] {
+			blockSize = p.optionalBlockSizes[i]
+			break
+		}
+	}
+	return blockSize
+}
+
+func hasPriorityOperation(txs []*tx.Tx) bool {
+	for _, poolTx := range txs {
+		if types.IsPriorityOperationTx(poolTx.TxType) {
+			return true
+		}
+	}
+	return false
+}
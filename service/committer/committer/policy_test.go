@@ -0,0 +1,69 @@
+package committer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestDefaultBlockPolicy(t *testing.T) {
+	policy := NewBlockPolicy(BlockPolicyConfig{
+		OptionalBlockSizes:    []int{1, 8, 32},
+		CommitTimeout:         60,
+		PriorityCommitTimeout: 5,
+		MinFillRatio:          0.75,
+	})
+	createdAt := time.Now()
+	txs := func(txTypes ...int64) []*tx.Tx {
+		res := make([]*tx.Tx, 0, len(txTypes))
+		for _, txType := range txTypes {
+			res = append(res, &tx.Tx{TxType: txType})
+		}
+		return res
+	}
+	transfers := func(n int) []*tx.Tx {
+		res := make([]*tx.Tx, 0, n)
+		for i := 0; i < n; i++ {
+			res = append(res, &tx.Tx{TxType: types.TxTypeTransfer})
+		}
+		return res
+	}
+
+	assert.Equal(t, 1, policy.BlockSize(1))
+	assert.Equal(t, 8, policy.BlockSize(2))
+	assert.Equal(t, 32, policy.BlockSize(32))
+
+	// empty block is never committed
+	assert.False(t, policy.ShouldCommit(createdAt, nil, 0, 0, createdAt.Add(time.Hour)))
+	// full block
+	assert.True(t, policy.ShouldCommit(createdAt, transfers(32), 10, 0, createdAt))
+	// commit timeout
+	assert.False(t, policy.ShouldCommit(createdAt, transfers(2), 0, 0, createdAt.Add(59*time.Second)))
+	assert.True(t, policy.ShouldCommit(createdAt, transfers(2), 0, 0, createdAt.Add(60*time.Second)))
+	// priority operations are committed earlier
+	assert.False(t, policy.ShouldCommit(createdAt, txs(types.TxTypeTransfer, types.TxTypeDeposit), 0, 0, createdAt.Add(4*time.Second)))
+	assert.True(t, policy.ShouldCommit(createdAt, txs(types.TxTypeTransfer, types.TxTypeDeposit), 0, 0, createdAt.Add(5*time.Second)))
+	assert.False(t, policy.ShouldCommit(createdAt, txs(types.TxTypeTransfer, types.TxTypeTransfer), 0, 0, createdAt.Add(5*time.Second)))
+	// so are the txs followed by priority requests not in the tx pool yet
+	assert.False(t, policy.ShouldCommit(createdAt, transfers(2), 0, 1, createdAt.Add(4*time.Second)))
+	assert.True(t, policy.ShouldCommit(createdAt, transfers(2), 0, 1, createdAt.Add(5*time.Second)))
+	assert.False(t, policy.ShouldCommit(createdAt, nil, 0, 1, createdAt.Add(5*time.Second)))
+	// fill ratio is only checked once the pool is drained
+	assert.True(t, policy.ShouldCommit(createdAt, transfers(1), 0, 0, createdAt))
+	assert.False(t, policy.ShouldCommit(createdAt, transfers(1), 3, 0, createdAt))
+	assert.True(t, policy.ShouldCommit(createdAt, transfers(6), 0, 0, createdAt))
+	assert.False(t, policy.ShouldCommit(createdAt, transfers(5), 0, 0, createdAt))
+}
+
+func TestDefaultBlockPolicyFallback(t *testing.T) {
+	policy := NewBlockPolicy(BlockPolicyConfig{
+		OptionalBlockSizes: []int{1, 8},
+	}).(*defaultBlockPolicy)
+	assert.Equal(t, MaxCommitterInterval*time.Second, policy.commitTimeout)
+	assert.Equal(t, MaxCommitterInterval*time.Second, policy.priorityCommitTimeout)
+	assert.Equal(t, float64(0), policy.minFillRatio)
+}
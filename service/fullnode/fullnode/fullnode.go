@@ -214,6 +214,19 @@ func (c *Fullnode) processNewBlock(curBlock *block.Block, blockSize int) (*block
 				return err
 			}
 		}
+		// create collections and count their nfts
+		if len(blockStates.PendingCollection) != 0 {
+			err = c.bc.DB().CollectionModel.CreateCollectionsInTransact(tx, blockStates.PendingCollection)
+			if err != nil {
+				return err
+			}
+		}
+		if len(blockStates.PendingCollectionNftCount) != 0 {
+			err = c.bc.DB().CollectionModel.UpdateNftCountsInTransact(tx, blockStates.PendingCollectionNftCount)
+			if err != nil {
+				return err
+			}
+		}
 
 		return c.bc.DB().BlockModel.CreateBlockInTransact(tx, blockStates.Block)
 	})
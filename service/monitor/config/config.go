@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/common/rpcpool"
 )
 
 type Config struct {
@@ -14,7 +16,28 @@ type Config struct {
 		ConfirmBlocksCount      uint64
 		MaxHandledBlocksCount   int64
 		KeptHistoryBlocksCount  int64 // KeptHistoryBlocksCount define the count of blocks to keep in table, old blocks will be cleaned
+		// Reorgs are rolled back for generic blocks only, governance blocks can be synced with
+		// a deeper confirmation, 0 means the same as ConfirmBlocksCount.
+		//nolint:staticcheck
+		GovernanceConfirmBlocksCount uint64 `json:",optional"`
+		// The sys config value of NetworkRPCSysConfigName can be a comma separated list of endpoints.
+		//nolint:staticcheck
+		RPCPool rpcpool.Config `json:",optional"`
 	}
+	// PriorityRequestWatchdog alerts the operator before the oldest priority request which
+	// is not executed on l1 expires, the l1 contract enters exodus mode once it does.
+	//nolint:staticcheck
+	PriorityRequestWatchdog struct {
+		// Number of l1 blocks left before expiration to start warning, 0 means the default.
+		//nolint:staticcheck
+		WarnBlocks int64 `json:",optional"`
+		// Number of l1 blocks left before expiration to raise critical alerts, 0 means the default.
+		//nolint:staticcheck
+		CriticalBlocks int64 `json:",optional"`
+		// Alerts are also posted as json to the webhook if it is set.
+		//nolint:staticcheck
+		WebhookUrl string `json:",optional"`
+	} `json:",optional"`
 	LogConf logx.LogConf
 }
 
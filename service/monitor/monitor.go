@@ -46,6 +46,16 @@ func Run(configFile string) error {
 		panic(err)
 	}
 
+	// watch the expiration of priority requests
+	if _, err := cronJob.AddFunc("@every 30s", func() {
+		err := m.CheckPriorityRequestExpiration()
+		if err != nil {
+			logx.Errorf("check priority request expiration error, %v", err)
+		}
+	}); err != nil {
+		panic(err)
+	}
+
 	// monitor governance blocks
 	if _, err := cronJob.AddFunc("@every 10s", func() {
 		err := m.MonitorGovernanceBlocks()
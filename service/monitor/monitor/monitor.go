@@ -26,6 +26,7 @@ import (
 
 	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
 	common2 "github.com/bnb-chain/zkbnb/common"
+	"github.com/bnb-chain/zkbnb/common/rpcpool"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
@@ -34,6 +35,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/proof"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/dao/withdrawal"
 	"github.com/bnb-chain/zkbnb/service/monitor/config"
 	"github.com/bnb-chain/zkbnb/types"
 )
@@ -55,7 +57,7 @@ var (
 type Monitor struct {
 	Config config.Config
 
-	cli *rpc.ProviderClient
+	rpcPool *rpcpool.Pool
 
 	zkbnbContractAddress      string
 	governanceContractAddress string
@@ -70,6 +72,10 @@ type Monitor struct {
 	L2AssetModel         asset.AssetModel
 	PriorityRequestModel priorityrequest.PriorityRequestModel
 	L1SyncedBlockModel   l1syncedblock.L1SyncedBlockModel
+	WithdrawalModel      withdrawal.WithdrawalModel
+
+	// alert level of the last priority request expiration check
+	expirationAlertLevel int
 }
 
 func NewMonitor(c config.Config) *Monitor {
@@ -89,6 +95,13 @@ func NewMonitor(c config.Config) *Monitor {
 		L1SyncedBlockModel:   l1syncedblock.NewL1SyncedBlockModel(db),
 		L2AssetModel:         asset.NewAssetModel(db),
 		SysConfigModel:       sysconfig.NewSysConfigModel(db),
+		WithdrawalModel:      withdrawal.NewWithdrawalModel(db),
+	}
+	// the l1 block of handled rollup txs is looked up to roll back reorgs
+	err = monitor.L1RollupTxModel.CreateL1RollupTxTable()
+	if err != nil {
+		logx.Severef("fatal error, cannot migrate l1 rollup tx table, err: %v", err)
+		panic(err)
 	}
 
 	zkbnbAddressConfig, err := monitor.SysConfigModel.GetSysConfigByName(types.ZkBNBContract)
@@ -113,14 +126,15 @@ func NewMonitor(c config.Config) *Monitor {
 	logx.Infof("ChainName: %s, zkbnbContractAddress: %s, networkRpc: %s",
 		c.ChainConfig.NetworkRPCSysConfigName, zkbnbAddressConfig.Value, networkRpc.Value)
 
-	bscRpcCli, err := rpc.NewClient(networkRpc.Value)
+	rpcPool, err := rpcpool.NewPool(rpcpool.ParseEndpoints(networkRpc.Value), c.ChainConfig.RPCPool)
 	if err != nil {
+		logx.Severef("fatal error, cannot create rpc pool, err: %v", err)
 		panic(err)
 	}
 
 	monitor.zkbnbContractAddress = zkbnbAddressConfig.Value
 	monitor.governanceContractAddress = governanceAddressConfig.Value
-	monitor.cli = bscRpcCli
+	monitor.rpcPool = rpcPool
 
 	if err := prometheus.Register(priorityOperationMetric); err != nil {
 		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
@@ -130,11 +144,24 @@ func NewMonitor(c config.Config) *Monitor {
 		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
 		panic(err)
 	}
+	if err := prometheus.Register(unexecutedPriorityRequestMetric); err != nil {
+		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
+		panic(err)
+	}
+	if err := prometheus.Register(priorityRequestExpirationMetric); err != nil {
+		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
+		panic(err)
+	}
+	if err := prometheus.Register(priorityRequestAlertLevelMetric); err != nil {
+		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
+		panic(err)
+	}
 
 	return monitor
 }
 
 func (m *Monitor) Shutdown() {
+	m.rpcPool.Stop()
 	sqlDB, err := m.db.DB()
 	if err == nil && sqlDB != nil {
 		err = sqlDB.Close()
@@ -144,7 +171,7 @@ func (m *Monitor) Shutdown() {
 	}
 }
 
-func (m *Monitor) getBlockRangeToSync(monitorType int) (int64, int64, error) {
+func (m *Monitor) getBlockRangeToSync(cli *rpc.ProviderClient, monitorType int) (int64, int64, error) {
 	latestHandledBlock, err := m.L1SyncedBlockModel.GetLatestL1SyncedBlockByType(monitorType)
 	var handledHeight int64
 	if err != nil {
@@ -158,12 +185,16 @@ func (m *Monitor) getBlockRangeToSync(monitorType int) (int64, int64, error) {
 	}
 
 	// get latest l1 block height(latest height - pendingBlocksCount)
-	latestHeight, err := m.cli.GetHeight()
+	latestHeight, err := cli.GetHeight()
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get l1 height, err: %v", err)
 	}
 
-	safeHeight := latestHeight - m.Config.ChainConfig.ConfirmBlocksCount
+	confirmBlocksCount := m.Config.ChainConfig.ConfirmBlocksCount
+	if monitorType == l1syncedblock.TypeGovernance && m.Config.ChainConfig.GovernanceConfirmBlocksCount > 0 {
+		confirmBlocksCount = m.Config.ChainConfig.GovernanceConfirmBlocksCount
+	}
+	safeHeight := latestHeight - confirmBlocksCount
 	safeHeight = uint64(common2.MinInt64(int64(safeHeight), handledHeight+m.Config.ChainConfig.MaxHandledBlocksCount))
 
 	return handledHeight + 1, int64(safeHeight), nil
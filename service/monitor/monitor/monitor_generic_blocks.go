@@ -37,11 +37,18 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
 	"github.com/bnb-chain/zkbnb/dao/proof"
 	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/dao/withdrawal"
 	types2 "github.com/bnb-chain/zkbnb/types"
 )
 
-func (m *Monitor) MonitorGenericBlocks() (err error) {
-	startHeight, endHeight, err := m.getBlockRangeToSync(l1syncedblock.TypeGeneric)
+// MonitorGenericBlocks syncs the events of the zkbnb contract. The headers are compared with the
+// logs and the synced blocks, so all the reads of a round go to the same rpc endpoint.
+func (m *Monitor) MonitorGenericBlocks() error {
+	return m.rpcPool.Do(m.monitorGenericBlocks)
+}
+
+func (m *Monitor) monitorGenericBlocks(cli *rpc.ProviderClient) (err error) {
+	startHeight, endHeight, err := m.getBlockRangeToSync(cli, l1syncedblock.TypeGeneric)
 	if err != nil {
 		logx.Errorf("get block range to sync error, err: %s", err.Error())
 		return err
@@ -53,12 +60,24 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 
 	logx.Infof("syncing generic l1 blocks from %d to %d", big.NewInt(startHeight), big.NewInt(endHeight))
 
-	priorityRequestCount, err := getPriorityRequestCount(m.cli, m.zkbnbContractAddress, uint64(startHeight), uint64(endHeight))
+	reorged, err := m.checkGenericBlocksReorg(cli, startHeight)
+	if err != nil {
+		return err
+	}
+	if reorged {
+		return m.rollbackGenericBlocks(cli)
+	}
+
+	// The count is checked against the logs, so it is read from a quorum of the endpoints.
+	quorumResult, err := m.rpcPool.QuorumRead(func(cli *rpc.ProviderClient) (interface{}, error) {
+		return getPriorityRequestCount(cli, m.zkbnbContractAddress, uint64(startHeight), uint64(endHeight))
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get priority request count, err: %v", err)
 	}
+	priorityRequestCount := quorumResult.(int)
 
-	logs, err := getZkBNBContractLogs(m.cli, m.zkbnbContractAddress, uint64(startHeight), uint64(endHeight))
+	logs, err := getZkBNBContractLogs(cli, m.zkbnbContractAddress, uint64(startHeight), uint64(endHeight))
 	if err != nil {
 		return fmt.Errorf("failed to get contract logs, err: %v", err)
 	}
@@ -70,16 +89,25 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 
 		relatedBlocks        = make(map[int64]*block.Block)
 		relatedBlockTxStatus = make(map[int64]int)
+
+		// withdrawal events by l1 tx hash, which are not linked to a block yet
+		withdrawalEvents   = make(map[string][]*WithdrawalEvent)
+		withdrawalTxHashes []string
+		newWithdrawals     []*withdrawal.Withdrawal
 	)
 	for _, vlog := range logs {
 		l1EventInfo := &L1Event{
 			TxHash: vlog.TxHash.Hex(),
 		}
 
-		logBlock, err := m.cli.GetBlockHeaderByNumber(big.NewInt(int64(vlog.BlockNumber)))
+		logBlock, err := cli.GetBlockHeaderByNumber(big.NewInt(int64(vlog.BlockNumber)))
 		if err != nil {
 			return fmt.Errorf("failed to get block header, err: %v", err)
 		}
+		// The logs and the header are served from different forks if a reorg happens in between.
+		if logBlock.Hash() != vlog.BlockHash {
+			return fmt.Errorf("block hash of log %s does not match block %d, try it again", vlog.TxHash.Hex(), vlog.BlockNumber)
+		}
 
 		switch vlog.Topics[0].Hex() {
 		case zkbnbLogNewPriorityRequestSigHash.Hex():
@@ -91,8 +119,20 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 				return fmt.Errorf("failed to convert NewPriorityRequest log, err: %v", err)
 			}
 			priorityRequests = append(priorityRequests, l2TxEventMonitorInfo)
-		case zkbnbLogWithdrawalSigHash.Hex():
-		case zkbnbLogWithdrawalPendingSigHash.Hex():
+		case zkbnbLogWithdrawalSigHash.Hex(), zkbnbLogWithdrawalPendingSigHash.Hex(),
+			zkbnbLogWithdrawNftSigHash.Hex(), zkbnbLogWithdrawalNftPendingSigHash.Hex():
+			event, err := convertLogToWithdrawalEvent(vlog)
+			if err != nil {
+				return fmt.Errorf("failed to convert withdrawal log, err: %v", err)
+			}
+			l1EventInfo.EventType = EventTypeWithdrawal
+			if event.Pending {
+				l1EventInfo.EventType = EventTypeWithdrawalPending
+			}
+			if _, ok := withdrawalEvents[l1EventInfo.TxHash]; !ok {
+				withdrawalTxHashes = append(withdrawalTxHashes, l1EventInfo.TxHash)
+			}
+			withdrawalEvents[l1EventInfo.TxHash] = append(withdrawalEvents[l1EventInfo.TxHash], event)
 		case zkbnbLogBlockCommitSigHash.Hex():
 			l1EventInfo.EventType = EventTypeCommittedBlock
 
@@ -133,6 +173,14 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 			relatedBlocks[blockHeight].VerifiedAt = int64(logBlock.Time)
 			relatedBlocks[blockHeight].BlockStatus = block.StatusVerifiedAndExecuted
 			relatedBlockTxStatus[blockHeight] = tx.StatusVerified
+
+			// the withdrawal events before are emitted while executing this block
+			withdrawals, err := m.matchWithdrawals(blockHeight, l1EventInfo.TxHash, withdrawalEvents[l1EventInfo.TxHash])
+			if err != nil {
+				return fmt.Errorf("failed to match withdrawals of block %d, err: %v", blockHeight, err)
+			}
+			delete(withdrawalEvents, l1EventInfo.TxHash)
+			newWithdrawals = append(newWithdrawals, withdrawals...)
 		case zkbnbLogBlocksRevertSigHash.Hex():
 			l1EventInfo.EventType = EventTypeRevertedBlock
 		default:
@@ -143,6 +191,31 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 	if priorityRequestCount != priorityRequestCountCheck {
 		return fmt.Errorf("new priority requests events not match, try it again")
 	}
+	priorityRequests, err = m.filterSyncedPriorityRequests(priorityRequests)
+	if err != nil {
+		return err
+	}
+
+	// the withdrawal events left are not emitted while executing blocks, but by claims
+	var claimedWithdrawals []*withdrawal.Withdrawal
+	for _, txHash := range withdrawalTxHashes {
+		events, ok := withdrawalEvents[txHash]
+		if !ok {
+			continue
+		}
+		delete(withdrawalEvents, txHash)
+		withdrawals, err := m.matchWithdrawalClaims(cli, txHash, events, newWithdrawals)
+		if err != nil {
+			return fmt.Errorf("failed to match withdrawal claims, err: %v", err)
+		}
+		claimedWithdrawals = append(claimedWithdrawals, withdrawals...)
+	}
+
+	// The hash of the end block is checked against the parent of the next range to detect reorgs.
+	endBlock, err := cli.GetBlockHeaderByNumber(big.NewInt(endHeight))
+	if err != nil {
+		return fmt.Errorf("failed to get block header, err: %v", err)
+	}
 
 	eventInfosBytes, err := json.Marshal(l1Events)
 	if err != nil {
@@ -150,6 +223,7 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 	}
 	l1BlockMonitorInfo := &l1syncedblock.L1SyncedBlock{
 		L1BlockHeight: endHeight,
+		L1BlockHash:   endBlock.Hash().Hex(),
 		BlockInfo:     string(eventInfosBytes),
 		Type:          l1syncedblock.TypeGeneric,
 	}
@@ -199,6 +273,19 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 		if err != nil {
 			return err
 		}
+		//create and update withdrawals
+		if len(newWithdrawals) != 0 {
+			err = m.WithdrawalModel.CreateWithdrawalsInTransact(tx, newWithdrawals)
+			if err != nil {
+				return err
+			}
+		}
+		if len(claimedWithdrawals) != 0 {
+			err = m.WithdrawalModel.UpdateWithdrawalsInTransact(tx, claimedWithdrawals)
+			if err != nil {
+				return err
+			}
+		}
 		// update l1 rollup tx status
 		// maybe already updated by sender, or may be deleted by sender because of timeout
 		for _, val := range pendingUpdateCommittedBlocks {
@@ -29,15 +29,16 @@ import (
 	"gorm.io/gorm"
 
 	zkbnb "github.com/bnb-chain/zkbnb-eth-rpc/core"
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/l1syncedblock"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/types"
 )
 
-func (m *Monitor) getNewL2Asset(event zkbnb.GovernanceNewAsset) (*asset.Asset, error) {
+func (m *Monitor) getNewL2Asset(cli *rpc.ProviderClient, event zkbnb.GovernanceNewAsset) (*asset.Asset, error) {
 	// get asset info by contract address
-	erc20Instance, err := zkbnb.LoadERC20(m.cli, event.AssetAddress.Hex())
+	erc20Instance, err := zkbnb.LoadERC20(cli, event.AssetAddress.Hex())
 	if err != nil {
 		return nil, err
 	}
@@ -81,8 +82,12 @@ func NewGovernancePendingChanges() *GovernancePendingChanges {
 	}
 }
 
-func (m *Monitor) MonitorGovernanceBlocks() (err error) {
-	startHeight, endHeight, err := m.getBlockRangeToSync(l1syncedblock.TypeGovernance)
+func (m *Monitor) MonitorGovernanceBlocks() error {
+	return m.rpcPool.Do(m.monitorGovernanceBlocks)
+}
+
+func (m *Monitor) monitorGovernanceBlocks(cli *rpc.ProviderClient) (err error) {
+	startHeight, endHeight, err := m.getBlockRangeToSync(cli, l1syncedblock.TypeGovernance)
 	if err != nil {
 		logx.Errorf("get block range to sync error, err: %s", err.Error())
 		return err
@@ -99,7 +104,7 @@ func (m *Monitor) MonitorGovernanceBlocks() (err error) {
 		ToBlock:   big.NewInt(endHeight),
 		Addresses: []common.Address{contractAddress},
 	}
-	logs, err := m.cli.FilterLogs(context.Background(), query)
+	logs, err := cli.FilterLogs(context.Background(), query)
 	if err != nil {
 		return fmt.Errorf("failed to query logs through rpc client: %v", err)
 	}
@@ -118,7 +123,7 @@ func (m *Monitor) MonitorGovernanceBlocks() (err error) {
 				EventType: EventTypeAddAsset,
 				TxHash:    vlog.TxHash.Hex(),
 			}
-			newL2Asset, err := m.getNewL2Asset(event)
+			newL2Asset, err := m.getNewL2Asset(cli, event)
 			if err != nil {
 				logx.Infof("get new l2 asset error, err: %s", err.Error())
 				return err
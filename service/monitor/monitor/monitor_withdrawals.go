@@ -0,0 +1,285 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	zkbnb "github.com/bnb-chain/zkbnb-eth-rpc/core"
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/dao/withdrawal"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	MethodNameWithdrawPendingBalance    = "withdrawPendingBalance"
+	MethodNameWithdrawPendingNFTBalance = "withdrawPendingNFTBalance"
+)
+
+// WithdrawalEvent is a withdrawal log of the zkbnb contract. The contract emits them while
+// executing the pending on-chain operations of a block, right before the BlockVerification
+// log of the block, and when the owner claims the pending balance.
+type WithdrawalEvent struct {
+	Pending bool
+	Nft     bool
+	// asset withdrawals only
+	AssetId int64
+	Amount  *big.Int
+	// pending nft withdrawals only
+	NftIndex int64
+	// nft withdrawals only
+	AccountIndex int64
+	ToAddress    string
+}
+
+func convertLogToWithdrawalEvent(vlog types.Log) (*WithdrawalEvent, error) {
+	switch vlog.Topics[0].Hex() {
+	case zkbnbLogWithdrawalSigHash.Hex(), zkbnbLogWithdrawalPendingSigHash.Hex():
+		// WithdrawalPending shares the fields of Withdrawal.
+		var event zkbnb.ZkBNBWithdrawal
+		if err := ZkBNBContractAbi.UnpackIntoInterface(&event, EventNameWithdrawal, vlog.Data); err != nil {
+			return nil, err
+		}
+		return &WithdrawalEvent{
+			Pending: vlog.Topics[0] == zkbnbLogWithdrawalPendingSigHash,
+			AssetId: int64(event.AssetId),
+			Amount:  event.Amount,
+		}, nil
+	case zkbnbLogWithdrawNftSigHash.Hex():
+		var event zkbnb.ZkBNBWithdrawNft
+		if err := ZkBNBContractAbi.UnpackIntoInterface(&event, EventNameWithdrawNft, vlog.Data); err != nil {
+			return nil, err
+		}
+		return &WithdrawalEvent{
+			Nft:          true,
+			AccountIndex: int64(event.AccountIndex),
+			ToAddress:    event.ToAddress.Hex(),
+		}, nil
+	case zkbnbLogWithdrawalNftPendingSigHash.Hex():
+		if len(vlog.Topics) < 2 {
+			return nil, fmt.Errorf("nft index is missing in the log")
+		}
+		return &WithdrawalEvent{
+			Pending:  true,
+			Nft:      true,
+			NftIndex: vlog.Topics[1].Big().Int64(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown withdrawal log %s", vlog.Topics[0].Hex())
+	}
+}
+
+// matchWithdrawals links the withdrawal events emitted while executing a block with the
+// withdraw, withdraw nft and full exit txs of the block, following the order of the txs.
+func (m *Monitor) matchWithdrawals(blockHeight int64, l1TxHash string, events []*WithdrawalEvent) ([]*withdrawal.Withdrawal, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	l2Block, err := m.BlockModel.GetBlockByHeight(blockHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d, err: %v", blockHeight, err)
+	}
+
+	var candidates []*withdrawal.Withdrawal
+	for _, blockTx := range l2Block.Txs {
+		if !isWithdrawalTx(blockTx.TxType) {
+			continue
+		}
+		candidate, err := newWithdrawal(blockTx)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	withdrawals := make([]*withdrawal.Withdrawal, 0, len(events))
+	matched := make([]bool, len(candidates))
+	for _, event := range events {
+		index := -1
+		for i, candidate := range candidates {
+			if !matched[i] && event.matches(candidate) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			logx.Errorf("no tx in block %d matches the withdrawal event %+v of l1 tx %s", blockHeight, event, l1TxHash)
+			continue
+		}
+		matched[index] = true
+
+		w := candidates[index]
+		w.L1TxHash = l1TxHash
+		w.Status = withdrawal.StatusWithdrawn
+		if event.Pending {
+			w.Status = withdrawal.StatusPendingBalance
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, nil
+}
+
+func (e *WithdrawalEvent) matches(w *withdrawal.Withdrawal) bool {
+	isNftTx := w.TxType == types2.TxTypeWithdrawNft || w.TxType == types2.TxTypeFullExitNft
+	if e.Nft != isNftTx {
+		return false
+	}
+	if !e.Nft {
+		return e.AssetId == w.AssetId && e.Amount.String() == w.AssetAmount
+	}
+	if e.Pending {
+		return e.NftIndex == w.NftIndex
+	}
+	return e.AccountIndex == w.AccountIndex && strings.EqualFold(e.ToAddress, w.ToAddress)
+}
+
+// matchWithdrawalClaims marks the pending balance withdrawals claimed by an l1 tx, which
+// calls withdrawPendingBalance or withdrawPendingNFTBalance of the contract. The pending
+// balance of an asset is claimed as a whole, the withdrawals are claimed in order as long as
+// the claimed amount covers them. Withdrawals created in the same batch are passed in
+// newWithdrawals, they are updated in place, the others are returned.
+func (m *Monitor) matchWithdrawalClaims(cli *rpc.ProviderClient, l1TxHash string, events []*WithdrawalEvent,
+	newWithdrawals []*withdrawal.Withdrawal) ([]*withdrawal.Withdrawal, error) {
+	claimTx, _, err := cli.GetTransactionByHash(l1TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tx %s, err: %v", l1TxHash, err)
+	}
+	if len(claimTx.Data()) < 4 {
+		logx.Errorf("withdrawal events of l1 tx %s are not emitted by a claim", l1TxHash)
+		return nil, nil
+	}
+	method, err := ZkBNBContractAbi.MethodById(claimTx.Data()[:4])
+	if err != nil {
+		// The claim is called by another contract.
+		logx.Errorf("withdrawal events of l1 tx %s are not emitted by a claim", l1TxHash)
+		return nil, nil
+	}
+	args, err := method.Inputs.Unpack(claimTx.Data()[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack input of l1 tx %s, err: %v", l1TxHash, err)
+	}
+
+	var (
+		candidates         []*withdrawal.Withdrawal
+		claimedWithdrawals []*withdrawal.Withdrawal
+	)
+	switch method.Name {
+	case MethodNameWithdrawPendingBalance:
+		owner := args[0].(common.Address).Hex()
+		candidates, err = m.WithdrawalModel.GetPendingBalanceWithdrawalsByAddress(owner)
+		if err != nil && err != types2.DbErrNotFound {
+			return nil, fmt.Errorf("failed to get pending balance withdrawals of %s, err: %v", owner, err)
+		}
+		for _, w := range newWithdrawals {
+			if w.Status == withdrawal.StatusPendingBalance && w.ToAddress == owner {
+				candidates = append(candidates, w)
+			}
+		}
+	case MethodNameWithdrawPendingNFTBalance:
+		nftIndex := args[0].(*big.Int).Int64()
+		candidate, err := m.WithdrawalModel.GetPendingBalanceWithdrawalByNftIndex(nftIndex)
+		if err != nil && err != types2.DbErrNotFound {
+			return nil, fmt.Errorf("failed to get pending balance withdrawal of nft %d, err: %v", nftIndex, err)
+		}
+		if candidate != nil {
+			candidates = append(candidates, candidate)
+		}
+		for _, w := range newWithdrawals {
+			if w.Status == withdrawal.StatusPendingBalance && w.NftIndex == nftIndex &&
+				(w.TxType == types2.TxTypeWithdrawNft || w.TxType == types2.TxTypeFullExitNft) {
+				candidates = append(candidates, w)
+			}
+		}
+	default:
+		logx.Errorf("withdrawal events of l1 tx %s are not emitted by a claim", l1TxHash)
+		return nil, nil
+	}
+
+	for _, event := range events {
+		claimedAmount := new(big.Int)
+		if event.Amount != nil {
+			claimedAmount.Set(event.Amount)
+		}
+		for _, w := range candidates {
+			if w.Status != withdrawal.StatusPendingBalance {
+				continue
+			}
+			if event.Nft {
+				if w.TxType != types2.TxTypeWithdrawNft && w.TxType != types2.TxTypeFullExitNft {
+					continue
+				}
+			} else {
+				amount, ok := new(big.Int).SetString(w.AssetAmount, 10)
+				if !ok || w.AssetId != event.AssetId || amount.Cmp(claimedAmount) > 0 {
+					continue
+				}
+				claimedAmount.Sub(claimedAmount, amount)
+			}
+			w.Status = withdrawal.StatusClaimed
+			w.ClaimTxHash = l1TxHash
+			if w.ID != 0 {
+				claimedWithdrawals = append(claimedWithdrawals, w)
+			}
+			if event.Nft {
+				break
+			}
+		}
+	}
+	return claimedWithdrawals, nil
+}
+
+func isWithdrawalTx(txType int64) bool {
+	return txType == types2.TxTypeWithdraw || txType == types2.TxTypeWithdrawNft ||
+		txType == types2.TxTypeFullExit || txType == types2.TxTypeFullExitNft
+}
+
+func newWithdrawal(blockTx *tx.Tx) (*withdrawal.Withdrawal, error) {
+	w := &withdrawal.Withdrawal{
+		L2TxHash:      blockTx.TxHash,
+		TxType:        blockTx.TxType,
+		AccountIndex:  blockTx.AccountIndex,
+		L2BlockHeight: blockTx.BlockHeight,
+		AssetId:       blockTx.AssetId,
+		AssetAmount:   blockTx.TxAmount,
+		NftIndex:      blockTx.NftIndex,
+	}
+	switch blockTx.TxType {
+	case types2.TxTypeWithdraw:
+		txInfo, err := types2.ParseWithdrawTxInfo(blockTx.TxInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse withdraw tx %s, err: %v", blockTx.TxHash, err)
+		}
+		w.ToAddress = common.HexToAddress(txInfo.ToAddress).Hex()
+	case types2.TxTypeWithdrawNft:
+		txInfo, err := types2.ParseWithdrawNftTxInfo(blockTx.TxInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse withdraw nft tx %s, err: %v", blockTx.TxHash, err)
+		}
+		w.ToAddress = common.HexToAddress(txInfo.ToAddress).Hex()
+	default:
+		// Full exits are sent to the l1 address which requests them.
+		w.ToAddress = common.HexToAddress(blockTx.NativeAddress).Hex()
+	}
+	return w, nil
+}
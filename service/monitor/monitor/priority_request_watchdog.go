@@ -0,0 +1,200 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	// BSC produces a block every 3 seconds, warn one day and go critical 4 hours ahead.
+	defaultExpirationWarnBlocks     = 28800
+	defaultExpirationCriticalBlocks = 4800
+
+	webhookTimeout = 5 * time.Second
+)
+
+const (
+	AlertLevelNone = iota
+	AlertLevelWarn
+	AlertLevelCritical
+	AlertLevelExpired
+)
+
+var (
+	unexecutedPriorityRequestMetric = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "zkbnb",
+		Name:      "priority_request_unexecuted_count",
+		Help:      "Number of priority requests which are not executed on l1 yet.",
+	})
+
+	// priorityRequestExpirationMetric has no labels, it is a vector only so that the series
+	// can be removed while no priority request is pending, instead of reading 0 blocks left.
+	priorityRequestExpirationMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "zkbnb",
+		Name:      "priority_request_expiration_remaining_blocks",
+		Help:      "L1 blocks left before the oldest unexecuted priority request expires, absent when none is pending.",
+	}, nil)
+
+	priorityRequestAlertLevelMetric = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "zkbnb",
+		Name:      "priority_request_expiration_alert_level",
+		Help:      "Priority request expiration alert level, 0: none, 1: warn, 2: critical, 3: expired.",
+	})
+)
+
+type PriorityRequestAlert struct {
+	Level           string `json:"level"`
+	RequestId       int64  `json:"request_id"`
+	L1TxHash        string `json:"l1_tx_hash"`
+	ExpirationBlock int64  `json:"expiration_block"`
+	L1BlockHeight   int64  `json:"l1_block_height"`
+	RemainingBlocks int64  `json:"remaining_blocks"`
+	PendingCount    int64  `json:"pending_count"`
+}
+
+// CheckPriorityRequestExpiration compares the oldest priority request which is not executed
+// on l1 with the current l1 height, and alerts when its expiration block approaches.
+func (m *Monitor) CheckPriorityRequestExpiration() error {
+	executedHeight, err := m.BlockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		if err != types.DbErrNotFound {
+			return fmt.Errorf("failed to get latest verified height, err: %v", err)
+		}
+		executedHeight = 0
+	}
+
+	pendingCount, err := m.PriorityRequestModel.GetUnexecutedPriorityRequestCount(executedHeight)
+	if err != nil {
+		return fmt.Errorf("failed to count unexecuted priority requests, err: %v", err)
+	}
+	unexecutedPriorityRequestMetric.Set(float64(pendingCount))
+
+	oldestRequest, err := m.PriorityRequestModel.GetOldestUnexecutedPriorityRequest(executedHeight)
+	if err != nil {
+		if err != types.DbErrNotFound {
+			return fmt.Errorf("failed to get oldest unexecuted priority request, err: %v", err)
+		}
+		priorityRequestExpirationMetric.Reset()
+		m.escalatePriorityRequestAlert(AlertLevelNone, &PriorityRequestAlert{PendingCount: pendingCount})
+		return nil
+	}
+
+	latestHeight, err := m.rpcPool.Client().GetHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get l1 height, err: %v", err)
+	}
+
+	alert := &PriorityRequestAlert{
+		RequestId:       oldestRequest.RequestId,
+		L1TxHash:        oldestRequest.L1TxHash,
+		ExpirationBlock: oldestRequest.ExpirationBlock,
+		L1BlockHeight:   int64(latestHeight),
+		RemainingBlocks: oldestRequest.ExpirationBlock - int64(latestHeight),
+		PendingCount:    pendingCount,
+	}
+	priorityRequestExpirationMetric.WithLabelValues().Set(float64(alert.RemainingBlocks))
+	m.escalatePriorityRequestAlert(m.priorityRequestAlertLevel(alert.RemainingBlocks), alert)
+	return nil
+}
+
+func (m *Monitor) priorityRequestAlertLevel(remainingBlocks int64) int {
+	warnBlocks := m.Config.PriorityRequestWatchdog.WarnBlocks
+	if warnBlocks <= 0 {
+		warnBlocks = defaultExpirationWarnBlocks
+	}
+	criticalBlocks := m.Config.PriorityRequestWatchdog.CriticalBlocks
+	if criticalBlocks <= 0 {
+		criticalBlocks = defaultExpirationCriticalBlocks
+	}
+
+	switch {
+	case remainingBlocks <= 0:
+		return AlertLevelExpired
+	case remainingBlocks <= criticalBlocks:
+		return AlertLevelCritical
+	case remainingBlocks <= warnBlocks:
+		return AlertLevelWarn
+	default:
+		return AlertLevelNone
+	}
+}
+
+// escalatePriorityRequestAlert logs the alert on every check while it lasts, but only posts
+// to the webhook when the alert level changes, so that the operator is not flooded. Going
+// back to AlertLevelNone posts a resolved alert.
+func (m *Monitor) escalatePriorityRequestAlert(level int, alert *PriorityRequestAlert) {
+	priorityRequestAlertLevelMetric.Set(float64(level))
+	previousLevel := m.expirationAlertLevel
+	m.expirationAlertLevel = level
+
+	switch level {
+	case AlertLevelNone:
+		alert.Level = "resolved"
+		if previousLevel != AlertLevelNone {
+			logx.Infof("priority request expiration alert is resolved")
+		}
+	case AlertLevelWarn:
+		alert.Level = "warn"
+		logx.Errorf("priority request %d expires in %d l1 blocks at height %d, %d priority requests are not executed",
+			alert.RequestId, alert.RemainingBlocks, alert.ExpirationBlock, alert.PendingCount)
+	case AlertLevelCritical:
+		alert.Level = "critical"
+		logx.Severef("priority request %d expires in %d l1 blocks at height %d, %d priority requests are not executed",
+			alert.RequestId, alert.RemainingBlocks, alert.ExpirationBlock, alert.PendingCount)
+	case AlertLevelExpired:
+		alert.Level = "expired"
+		logx.Severef("priority request %d expired at l1 height %d, the contract can enter exodus mode",
+			alert.RequestId, alert.ExpirationBlock)
+	}
+
+	if level != previousLevel && m.Config.PriorityRequestWatchdog.WebhookUrl != "" {
+		err := postPriorityRequestAlert(m.Config.PriorityRequestWatchdog.WebhookUrl, alert)
+		if err != nil {
+			logx.Errorf("failed to post priority request alert to webhook, err: %v", err)
+			// Post it again in the next check.
+			m.expirationAlertLevel = previousLevel
+		}
+	}
+}
+
+func postPriorityRequestAlert(url string, alert *PriorityRequestAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
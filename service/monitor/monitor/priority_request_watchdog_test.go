@@ -0,0 +1,86 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityRequestAlertLevel(t *testing.T) {
+	m := &Monitor{}
+
+	// default thresholds
+	assert.Equal(t, AlertLevelNone, m.priorityRequestAlertLevel(defaultExpirationWarnBlocks+1))
+	assert.Equal(t, AlertLevelWarn, m.priorityRequestAlertLevel(defaultExpirationWarnBlocks))
+	assert.Equal(t, AlertLevelWarn, m.priorityRequestAlertLevel(defaultExpirationCriticalBlocks+1))
+	assert.Equal(t, AlertLevelCritical, m.priorityRequestAlertLevel(defaultExpirationCriticalBlocks))
+	assert.Equal(t, AlertLevelCritical, m.priorityRequestAlertLevel(1))
+	assert.Equal(t, AlertLevelExpired, m.priorityRequestAlertLevel(0))
+	assert.Equal(t, AlertLevelExpired, m.priorityRequestAlertLevel(-10))
+
+	// configured thresholds
+	m.Config.PriorityRequestWatchdog.WarnBlocks = 100
+	m.Config.PriorityRequestWatchdog.CriticalBlocks = 10
+	assert.Equal(t, AlertLevelNone, m.priorityRequestAlertLevel(101))
+	assert.Equal(t, AlertLevelWarn, m.priorityRequestAlertLevel(100))
+	assert.Equal(t, AlertLevelWarn, m.priorityRequestAlertLevel(11))
+	assert.Equal(t, AlertLevelCritical, m.priorityRequestAlertLevel(10))
+	assert.Equal(t, AlertLevelExpired, m.priorityRequestAlertLevel(0))
+}
+
+func TestEscalatePriorityRequestAlert(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alert := &PriorityRequestAlert{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(alert))
+		posted = append(posted, alert.Level)
+	}))
+	defer server.Close()
+
+	m := &Monitor{}
+	m.Config.PriorityRequestWatchdog.WebhookUrl = server.URL
+
+	// nothing is posted until an alert is raised
+	m.escalatePriorityRequestAlert(AlertLevelNone, &PriorityRequestAlert{})
+	assert.Empty(t, posted)
+
+	// only level changes are posted
+	m.escalatePriorityRequestAlert(AlertLevelWarn, &PriorityRequestAlert{RemainingBlocks: 100})
+	m.escalatePriorityRequestAlert(AlertLevelWarn, &PriorityRequestAlert{RemainingBlocks: 90})
+	m.escalatePriorityRequestAlert(AlertLevelCritical, &PriorityRequestAlert{RemainingBlocks: 10})
+	assert.Equal(t, []string{"warn", "critical"}, posted)
+
+	// the webhook is told that the alert is resolved
+	m.escalatePriorityRequestAlert(AlertLevelNone, &PriorityRequestAlert{})
+	m.escalatePriorityRequestAlert(AlertLevelNone, &PriorityRequestAlert{})
+	assert.Equal(t, []string{"warn", "critical", "resolved"}, posted)
+	assert.Equal(t, AlertLevelNone, m.expirationAlertLevel)
+}
+
+func TestPriorityRequestExpirationMetricReset(t *testing.T) {
+	priorityRequestExpirationMetric.WithLabelValues().Set(100)
+	assert.Equal(t, 1, testutil.CollectAndCount(priorityRequestExpirationMetric))
+
+	priorityRequestExpirationMetric.Reset()
+	assert.Equal(t, 0, testutil.CollectAndCount(priorityRequestExpirationMetric))
+}
@@ -0,0 +1,309 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/dao/l1syncedblock"
+	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
+	"github.com/bnb-chain/zkbnb/dao/proof"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// maxReorgSyncedBlocks is the number of synced generic blocks searched for the fork point.
+const maxReorgSyncedBlocks = 128
+
+// checkGenericBlocksReorg reports whether the l1 block before startHeight is still the block
+// recorded by the latest synced generic block, so that the new range extends the synced chain.
+func (m *Monitor) checkGenericBlocksReorg(cli *rpc.ProviderClient, startHeight int64) (bool, error) {
+	latestSyncedBlock, err := m.L1SyncedBlockModel.GetLatestL1SyncedBlockByType(l1syncedblock.TypeGeneric)
+	if err != nil {
+		if err == types.DbErrNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get latest l1 synced block, err: %v", err)
+	}
+	// Blocks synced by former versions do not record the hash.
+	if latestSyncedBlock.L1BlockHash == "" {
+		return false, nil
+	}
+	header, err := cli.GetBlockHeaderByNumber(big.NewInt(startHeight))
+	if err != nil {
+		return false, fmt.Errorf("failed to get block header, err: %v", err)
+	}
+	if header.ParentHash.Hex() == latestSyncedBlock.L1BlockHash {
+		return false, nil
+	}
+	logx.Severef("l1 reorg detected, parent hash %s of block %d does not match hash %s of synced block %d",
+		header.ParentHash.Hex(), startHeight, latestSyncedBlock.L1BlockHash, latestSyncedBlock.L1BlockHeight)
+	return true, nil
+}
+
+// rollbackGenericBlocks finds the latest synced generic block which is still on the canonical
+// chain, and rolls back everything recorded from the orphaned blocks after it, so that they
+// are synced again from the canonical chain.
+func (m *Monitor) rollbackGenericBlocks(cli *rpc.ProviderClient) error {
+	syncedBlocks, err := m.L1SyncedBlockModel.GetL1SyncedBlocksByType(l1syncedblock.TypeGeneric, maxReorgSyncedBlocks)
+	if err != nil {
+		return fmt.Errorf("failed to get l1 synced blocks, err: %v", err)
+	}
+	forkHeight, orphanedEvents, err := findForkPoint(syncedBlocks, func(height int64) (string, error) {
+		header, err := cli.GetBlockHeaderByNumber(big.NewInt(height))
+		if err != nil {
+			return "", err
+		}
+		return header.Hash().Hex(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The handled priority requests are executed in l2 blocks already, which cannot be rolled
+	// back, so the monitor stops advancing until the orphaned requests are resolved manually.
+	handledRequests, err := m.PriorityRequestModel.GetHandledPriorityRequestsForHeightGreaterThan(forkHeight)
+	if err != nil && err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get handled priority requests, err: %v", err)
+	}
+	if requestIds := orphanedHandledPriorityRequests(handledRequests, forkHeight); len(requestIds) != 0 {
+		logx.Severef("priority requests %v executed in l2 blocks are synced from l1 blocks orphaned after height %d, "+
+			"the monitor stops until they are resolved", requestIds, forkHeight)
+		return fmt.Errorf("executed priority requests %v are orphaned by a l1 reorg", requestIds)
+	}
+
+	revertBlocks, revertTxStatus, revertProofStatus, err := m.revertOrphanedBlocks(orphanedEvents)
+	if err != nil {
+		return err
+	}
+	orphanedTxHashes := make([]string, 0, len(orphanedEvents))
+	for _, event := range orphanedEvents {
+		orphanedTxHashes = append(orphanedTxHashes, event.TxHash)
+	}
+
+	// The handled l1 rollup txs mined in orphaned blocks are pending again, the sender checks
+	// whether they are mined on the canonical chain, and sends the blocks again otherwise.
+	handledRollupTxs, err := m.L1RollupTxModel.GetHandledTxsForL1HeightGreaterThan(forkHeight, orphanedTxHashes)
+	if err != nil && err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get handled l1 rollup txs, err: %v", err)
+	}
+	revertRollupTxs := revertOrphanedRollupTxs(handledRollupTxs, forkHeight, orphanedTxHashes)
+
+	err = m.db.Transaction(func(dbTx *gorm.DB) error {
+		// No handled priority request is synced from the orphaned blocks, see above.
+		err := m.PriorityRequestModel.DeletePendingPriorityRequestsForHeightGreaterThanInTransact(dbTx, forkHeight)
+		if err != nil {
+			return err
+		}
+		if len(revertBlocks) != 0 {
+			err = m.BlockModel.UpdateBlocksWithoutTxsInTransact(dbTx, revertBlocks)
+			if err != nil {
+				return err
+			}
+			err = m.TxModel.UpdateTxsStatusInTransact(dbTx, revertTxStatus)
+			if err != nil {
+				return err
+			}
+		}
+		if len(revertProofStatus) != 0 {
+			err = m.ProofModel.UpdateProofsInTransact(dbTx, revertProofStatus)
+			if err != nil {
+				return err
+			}
+		}
+		if len(orphanedTxHashes) != 0 {
+			err = m.WithdrawalModel.RevertWithdrawalClaimsInTransact(dbTx, orphanedTxHashes)
+			if err != nil {
+				return err
+			}
+			err = m.WithdrawalModel.DeleteWithdrawalsByL1TxHashesInTransact(dbTx, orphanedTxHashes)
+			if err != nil {
+				return err
+			}
+		}
+		if len(revertRollupTxs) != 0 {
+			err = m.L1RollupTxModel.UpdateL1RollupTxsInTransact(dbTx, revertRollupTxs)
+			if err != nil {
+				return err
+			}
+		}
+		return m.L1SyncedBlockModel.DeleteL1SyncedBlocksForHeightGreaterThanInTransact(dbTx, l1syncedblock.TypeGeneric, forkHeight)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back orphaned l1 blocks, err: %v", err)
+	}
+	logx.Severef("rolled back l1 blocks after height %d, %d l2 blocks and %d l1 rollup txs are reverted",
+		forkHeight, len(revertBlocks), len(revertRollupTxs))
+	return nil
+}
+
+// findForkPoint walks the synced blocks from the latest one down to the first one whose hash
+// is still the hash of the canonical block at its height, and returns its height with the
+// events of the orphaned synced blocks after it.
+func findForkPoint(syncedBlocks []*l1syncedblock.L1SyncedBlock, blockHash func(height int64) (string, error)) (int64, []*L1Event, error) {
+	var orphanedEvents []*L1Event
+	for _, syncedBlock := range syncedBlocks {
+		if syncedBlock.L1BlockHash == "" {
+			return syncedBlock.L1BlockHeight, orphanedEvents, nil
+		}
+		hash, err := blockHash(syncedBlock.L1BlockHeight)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get block header, err: %v", err)
+		}
+		if hash == syncedBlock.L1BlockHash {
+			return syncedBlock.L1BlockHeight, orphanedEvents, nil
+		}
+		var events []*L1Event
+		err = json.Unmarshal([]byte(syncedBlock.BlockInfo), &events)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to unmarshal events of synced block %d, err: %v", syncedBlock.L1BlockHeight, err)
+		}
+		orphanedEvents = append(orphanedEvents, events...)
+	}
+	return 0, nil, fmt.Errorf("no synced block on the canonical chain in the latest %d synced blocks", len(syncedBlocks))
+}
+
+// orphanedHandledPriorityRequests returns the ids of the handled priority requests synced from
+// the l1 blocks after the fork height.
+func orphanedHandledPriorityRequests(requests []*priorityrequest.PriorityRequest, forkHeight int64) []int64 {
+	var requestIds []int64
+	for _, request := range requests {
+		if request.Status == priorityrequest.HandledStatus && request.L1BlockHeight > forkHeight {
+			requestIds = append(requestIds, request.RequestId)
+		}
+	}
+	return requestIds
+}
+
+// revertOrphanedRollupTxs returns the handled rollup txs mined after the fork height, or sent
+// by the orphaned txs for the ones which do not record the l1 height, marked pending again.
+func revertOrphanedRollupTxs(handledTxs []*l1rolluptx.L1RollupTx, forkHeight int64, orphanedTxHashes []string) []*l1rolluptx.L1RollupTx {
+	orphaned := make(map[string]bool, len(orphanedTxHashes))
+	for _, txHash := range orphanedTxHashes {
+		orphaned[txHash] = true
+	}
+	var revertTxs []*l1rolluptx.L1RollupTx
+	for _, handledTx := range handledTxs {
+		if handledTx.TxStatus != l1rolluptx.StatusHandled {
+			continue
+		}
+		if handledTx.L1BlockHeight <= forkHeight && !orphaned[handledTx.L1TxHash] {
+			continue
+		}
+		handledTx.TxStatus = l1rolluptx.StatusPending
+		handledTx.L1BlockHeight = 0
+		revertTxs = append(revertTxs, handledTx)
+	}
+	return revertTxs
+}
+
+// revertOrphanedBlocks returns the l2 blocks committed or verified by orphaned l1 txs with the
+// status they had before those txs.
+func (m *Monitor) revertOrphanedBlocks(orphanedEvents []*L1Event) ([]*block.Block, map[int64]int, map[int64]int, error) {
+	var (
+		txHashes          []string
+		committedTxHashes = make(map[string]bool)
+		verifiedTxHashes  = make(map[string]bool)
+	)
+	for _, event := range orphanedEvents {
+		switch event.EventType {
+		case EventTypeCommittedBlock:
+			committedTxHashes[event.TxHash] = true
+		case EventTypeVerifiedBlock:
+			verifiedTxHashes[event.TxHash] = true
+		default:
+			continue
+		}
+		txHashes = append(txHashes, event.TxHash)
+	}
+	if len(txHashes) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	blocks, err := m.BlockModel.GetBlocksByL1TxHashes(txHashes)
+	if err != nil {
+		if err == types.DbErrNotFound {
+			return nil, nil, nil, nil
+		}
+		return nil, nil, nil, fmt.Errorf("failed to get blocks by l1 tx hashes, err: %v", err)
+	}
+
+	revertTxStatus := make(map[int64]int)
+	revertProofStatus := make(map[int64]int)
+	for _, b := range blocks {
+		if verifiedTxHashes[b.VerifiedTxHash] {
+			b.VerifiedTxHash = ""
+			b.VerifiedAt = 0
+			b.BlockStatus = block.StatusCommitted
+			revertTxStatus[b.BlockHeight] = tx.StatusCommitted
+			revertProofStatus[b.BlockHeight] = proof.NotConfirmed
+		}
+		if committedTxHashes[b.CommittedTxHash] {
+			b.CommittedTxHash = ""
+			b.CommittedAt = 0
+			b.BlockStatus = block.StatusPending
+			revertTxStatus[b.BlockHeight] = tx.StatusPacked
+		}
+	}
+	return blocks, revertTxStatus, revertProofStatus, nil
+}
+
+// filterSyncedPriorityRequests drops the priority requests which are synced already, which
+// happens to the requests handled before a reorg and included again by the canonical chain.
+func (m *Monitor) filterSyncedPriorityRequests(requests []*priorityrequest.PriorityRequest) ([]*priorityrequest.PriorityRequest, error) {
+	if len(requests) == 0 {
+		return requests, nil
+	}
+	requestIds := make([]int64, 0, len(requests))
+	for _, request := range requests {
+		requestIds = append(requestIds, request.RequestId)
+	}
+	syncedRequests, err := m.PriorityRequestModel.GetPriorityRequestsByRequestIds(requestIds)
+	if err != nil {
+		if err == types.DbErrNotFound {
+			return requests, nil
+		}
+		return nil, fmt.Errorf("failed to get priority requests by request ids, err: %v", err)
+	}
+	syncedPubdata := make(map[int64]string, len(syncedRequests))
+	for _, request := range syncedRequests {
+		syncedPubdata[request.RequestId] = request.Pubdata
+	}
+
+	newRequests := make([]*priorityrequest.PriorityRequest, 0, len(requests))
+	for _, request := range requests {
+		pubdata, ok := syncedPubdata[request.RequestId]
+		if !ok {
+			newRequests = append(newRequests, request)
+			continue
+		}
+		if pubdata != request.Pubdata {
+			logx.Severef("priority request %d is executed with different pubdata before a reorg", request.RequestId)
+			return nil, fmt.Errorf("pubdata of priority request %d does not match the synced one", request.RequestId)
+		}
+		logx.Infof("priority request %d is synced already, skip it", request.RequestId)
+	}
+	return newRequests, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/dao/l1syncedblock"
+	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
+)
+
+func newSyncedBlock(t *testing.T, height int64, hash string, events ...*L1Event) *l1syncedblock.L1SyncedBlock {
+	info, err := json.Marshal(events)
+	assert.NoError(t, err)
+	return &l1syncedblock.L1SyncedBlock{L1BlockHeight: height, L1BlockHash: hash, BlockInfo: string(info)}
+}
+
+func TestRollbackHandledRollupTx(t *testing.T) {
+	// the commit tx of the synced block 120 is orphaned by a reorg after block 100
+	commitEvent := &L1Event{EventType: EventTypeCommittedBlock, TxHash: "0xcommit"}
+	syncedBlocks := []*l1syncedblock.L1SyncedBlock{
+		newSyncedBlock(t, 120, "0xorphaned120", commitEvent),
+		newSyncedBlock(t, 110, "0xorphaned110"),
+		newSyncedBlock(t, 100, "0xcanonical100"),
+		newSyncedBlock(t, 90, "0xcanonical90"),
+	}
+	canonicalHashes := map[int64]string{
+		120: "0xcanonical120",
+		110: "0xcanonical110",
+		100: "0xcanonical100",
+		90:  "0xcanonical90",
+	}
+	forkHeight, orphanedEvents, err := findForkPoint(syncedBlocks, func(height int64) (string, error) {
+		return canonicalHashes[height], nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), forkHeight)
+	assert.Len(t, orphanedEvents, 1)
+	assert.Equal(t, "0xcommit", orphanedEvents[0].TxHash)
+
+	handledTxs := []*l1rolluptx.L1RollupTx{
+		// mined before the fork
+		{L1TxHash: "0xkept", TxStatus: l1rolluptx.StatusHandled, L1BlockHeight: 95},
+		// handled by the sender, the monitor has synced its commit event
+		{L1TxHash: "0xcommit", TxStatus: l1rolluptx.StatusHandled, L1BlockHeight: 120},
+		// handled by the sender, but not synced by the monitor yet
+		{L1TxHash: "0xverify", TxStatus: l1rolluptx.StatusHandled, L1BlockHeight: 125},
+		// handled by a former version which does not record the l1 height
+		{L1TxHash: "0xcommit", TxStatus: l1rolluptx.StatusHandled},
+	}
+	revertTxs := revertOrphanedRollupTxs(handledTxs, forkHeight, []string{"0xcommit"})
+	assert.Equal(t, handledTxs[1:], revertTxs)
+	for _, revertTx := range revertTxs {
+		assert.Equal(t, l1rolluptx.StatusPending, revertTx.TxStatus)
+		assert.Equal(t, int64(0), revertTx.L1BlockHeight)
+	}
+	assert.Equal(t, l1rolluptx.StatusHandled, handledTxs[0].TxStatus)
+}
+
+func TestOrphanedHandledPriorityRequests(t *testing.T) {
+	// the reorg after block 100 orphans the requests synced from the blocks after it
+	requests := []*priorityrequest.PriorityRequest{
+		{RequestId: 1, Status: priorityrequest.HandledStatus, L1BlockHeight: 95},
+		{RequestId: 2, Status: priorityrequest.HandledStatus, L1BlockHeight: 100},
+		{RequestId: 3, Status: priorityrequest.HandledStatus, L1BlockHeight: 110},
+		{RequestId: 4, Status: priorityrequest.PendingStatus, L1BlockHeight: 120},
+		{RequestId: 5, Status: priorityrequest.HandledStatus, L1BlockHeight: 120},
+	}
+	// the executed requests 3 and 5 stop the monitor
+	assert.Equal(t, []int64{3, 5}, orphanedHandledPriorityRequests(requests, 100))
+	// the requests synced from the canonical blocks are kept
+	assert.Empty(t, orphanedHandledPriorityRequests(requests[:2], 100))
+	// the pending requests are deleted and synced again
+	assert.Empty(t, orphanedHandledPriorityRequests(requests[3:4], 100))
+	assert.Empty(t, orphanedHandledPriorityRequests(nil, 100))
+}
+
+func TestFindForkPoint(t *testing.T) {
+	// blocks synced by former versions do not record the hash and are taken as canonical
+	forkHeight, orphanedEvents, err := findForkPoint([]*l1syncedblock.L1SyncedBlock{
+		newSyncedBlock(t, 120, "0xorphaned120", &L1Event{TxHash: "0xa"}),
+		newSyncedBlock(t, 110, ""),
+	}, func(height int64) (string, error) {
+		return "0xcanonical", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(110), forkHeight)
+	assert.Len(t, orphanedEvents, 1)
+
+	// no synced block is canonical
+	_, _, err = findForkPoint([]*l1syncedblock.L1SyncedBlock{
+		newSyncedBlock(t, 120, "0xorphaned120"),
+	}, func(height int64) (string, error) {
+		return "0xcanonical", nil
+	})
+	assert.Error(t, err)
+
+	// the header lookup fails
+	_, _, err = findForkPoint([]*l1syncedblock.L1SyncedBlock{
+		newSyncedBlock(t, 120, "0xorphaned120"),
+	}, func(height int64) (string, error) {
+		return "", errors.New("not found")
+	})
+	assert.Error(t, err)
+}
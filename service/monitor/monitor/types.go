@@ -31,6 +31,8 @@ const (
 	EventNameNewPriorityRequest = "NewPriorityRequest"
 	EventNameBlockCommit        = "BlockCommit"
 	EventNameBlockVerification  = "BlockVerification"
+	EventNameWithdrawal         = "Withdrawal"
+	EventNameWithdrawNft        = "WithdrawNft"
 
 	EventTypeNewPriorityRequest = 0
 	EventTypeCommittedBlock     = 1
@@ -49,6 +51,9 @@ const (
 	EventTypeValidatorStatusUpdate = 7
 	EventTypeAssetPausedUpdate     = 8
 
+	EventTypeWithdrawal        = 9
+	EventTypeWithdrawalPending = 10
+
 	PendingStatus = priorityrequest.PendingStatus
 
 	TxTypeRegisterZns = types.TxTypeRegisterZns
@@ -75,6 +80,12 @@ var (
 	zkbnbLogBlockVerificationSigHash  = crypto.Keccak256Hash(zkbnbLogBlockVerificationSig)
 	zkbnbLogBlocksRevertSigHash       = crypto.Keccak256Hash(zkbnbLogBlocksRevertSig)
 
+	// NFT withdrawal logs sig
+	zkbnbLogWithdrawNftSig              = []byte("WithdrawNft(uint32,address,address,uint256)")
+	zkbnbLogWithdrawalNftPendingSig     = []byte("WithdrawalNFTPending(uint40)")
+	zkbnbLogWithdrawNftSigHash          = crypto.Keccak256Hash(zkbnbLogWithdrawNftSig)
+	zkbnbLogWithdrawalNftPendingSigHash = crypto.Keccak256Hash(zkbnbLogWithdrawalNftPendingSig)
+
 	GovernanceContractAbi, _ = abi.JSON(strings.NewReader(zkbnb.GovernanceMetaData.ABI))
 
 	governanceLogNewAssetSig              = []byte("NewAsset(address,uint16)")
@@ -18,4 +18,7 @@ type Config struct {
 	BlockConfig struct {
 		OptionalBlockSizes []int
 	}
+	// DryRun only checks that block witnesses satisfy the circuit, no proof is generated
+	// and the proving keys are not loaded.
+	DryRun bool `json:",optional"`
 }
@@ -52,3 +52,23 @@ func Run(configFile string) error {
 	<-exit
 	return nil
 }
+
+// CheckWitness checks the block witness of the given height against the circuit
+// and returns the unsatisfied constraint, if any, without generating a proof.
+func CheckWitness(configFile string, height int64) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	c.DryRun = true
+	p := prover.NewProver(c)
+	defer p.Shutdown()
+
+	err := p.CheckBlockWitness(height)
+	if err != nil {
+		return err
+	}
+	logx.Infof("block witness %d satisfies the circuit", height)
+	return nil
+}
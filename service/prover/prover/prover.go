@@ -13,7 +13,6 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
-	"github.com/bnb-chain/zkbnb-crypto/circuit"
 	"github.com/bnb-chain/zkbnb/common/prove"
 	"github.com/bnb-chain/zkbnb/common/redislock"
 	"github.com/bnb-chain/zkbnb/dao/blockwitness"
@@ -35,6 +34,9 @@ type Prover struct {
 	ProvingKeys        []groth16.ProvingKey
 	OptionalBlockSizes []int
 	R1cs               []frontend.CompiledConstraintSystem
+
+	// next block witness to check in dry-run mode
+	dryRunHeight int64
 }
 
 func WithRedis(redisType string, redisPass string) redis.Option {
@@ -75,19 +77,14 @@ func NewProver(c config.Config) *Prover {
 	prover.ProvingKeys = make([]groth16.ProvingKey, len(prover.OptionalBlockSizes))
 	prover.VerifyingKeys = make([]groth16.VerifyingKey, len(prover.OptionalBlockSizes))
 	prover.R1cs = make([]frontend.CompiledConstraintSystem, len(prover.OptionalBlockSizes))
+	if c.DryRun {
+		logx.Info("prover is running in dry-run mode, skip compiling circuits and loading keys")
+		return prover
+	}
 	for i := 0; i < len(prover.OptionalBlockSizes); i++ {
-		var blockConstraints circuit.BlockConstraints
-		blockConstraints.TxsCount = prover.OptionalBlockSizes[i]
-		blockConstraints.Txs = make([]circuit.TxConstraints, blockConstraints.TxsCount)
-		for i := 0; i < blockConstraints.TxsCount; i++ {
-			blockConstraints.Txs[i] = circuit.GetZeroTxConstraint()
-		}
-		blockConstraints.GasAssetIds = types.GasAssets[:]
-		blockConstraints.GasAccountIndex = types.GasAccount
-		blockConstraints.Gas = circuit.GetZeroGasConstraints(types.GasAssets[:])
-
+		blockConstraints := prove.NewBlockConstraints(prover.OptionalBlockSizes[i])
 		logx.Infof("start compile block size %d blockConstraints", blockConstraints.TxsCount)
-		prover.R1cs[i], err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &blockConstraints, frontend.IgnoreUnconstrainedInputs())
+		prover.R1cs[i], err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, blockConstraints, frontend.IgnoreUnconstrainedInputs())
 		if err != nil {
 			panic("r1cs init error")
 		}
@@ -108,6 +105,10 @@ func NewProver(c config.Config) *Prover {
 }
 
 func (p *Prover) ProveBlock() error {
+	if p.Config.DryRun {
+		return p.dryRunBlock()
+	}
+
 	blockWitness, err := func() (*blockwitness.BlockWitness, error) {
 		lock := redislock.GetRedisLockByKey(p.RedisConn, RedisLockKey)
 		err := redislock.TryAcquireLock(lock)
@@ -148,8 +149,7 @@ func (p *Prover) ProveBlock() error {
 	}()
 
 	// Parse crypto block.
-	var cryptoBlock *circuit.Block
-	err = json.Unmarshal([]byte(blockWitness.WitnessData), &cryptoBlock)
+	cryptoBlock, err := prove.ParseBlockWitness(blockWitness)
 	if err != nil {
 		return err
 	}
@@ -197,6 +197,64 @@ func (p *Prover) ProveBlock() error {
 	return err
 }
 
+// CheckBlockWitness checks the witness of the given height against the block circuit
+// without generating a proof, the returned error points to the unsatisfied constraint.
+func (p *Prover) CheckBlockWitness(height int64) error {
+	blockWitness, err := p.BlockWitnessModel.GetBlockWitnessByHeight(height)
+	if err != nil {
+		return fmt.Errorf("failed to get block witness %d, err: %v", height, err)
+	}
+	return p.checkBlockWitness(blockWitness)
+}
+
+func (p *Prover) checkBlockWitness(blockWitness *blockwitness.BlockWitness) error {
+	cryptoBlock, err := prove.ParseBlockWitness(blockWitness)
+	if err != nil {
+		return fmt.Errorf("failed to parse block witness %d, err: %v", blockWitness.Height, err)
+	}
+	var keyIndex int
+	for ; keyIndex < len(p.OptionalBlockSizes); keyIndex++ {
+		if len(cryptoBlock.Txs) == p.OptionalBlockSizes[keyIndex] {
+			break
+		}
+	}
+	if keyIndex == len(p.OptionalBlockSizes) {
+		return fmt.Errorf("block size %d of witness %d is not in the optional block sizes", len(cryptoBlock.Txs), blockWitness.Height)
+	}
+	return prove.CheckBlockSatisfiability(cryptoBlock)
+}
+
+// dryRunBlock walks the block witnesses in height order and checks each of them,
+// the witness status is left untouched so that real provers are not affected.
+func (p *Prover) dryRunBlock() error {
+	if p.dryRunHeight == 0 {
+		blockWitness, err := p.BlockWitnessModel.GetLatestBlockWitness()
+		if err != nil {
+			if err == types.DbErrNotFound {
+				return nil
+			}
+			return err
+		}
+		p.dryRunHeight = blockWitness.Height
+	}
+
+	blockWitness, err := p.BlockWitnessModel.GetBlockWitnessByHeight(p.dryRunHeight)
+	if err != nil {
+		if err == types.DbErrNotFound {
+			return nil
+		}
+		return err
+	}
+	err = p.checkBlockWitness(blockWitness)
+	if err != nil {
+		logx.Errorf("dry run of block witness %d failed, %v", p.dryRunHeight, err)
+	} else {
+		logx.Infof("block witness %d satisfies the circuit", p.dryRunHeight)
+	}
+	p.dryRunHeight++
+	return nil
+}
+
 func (p *Prover) Shutdown() {
 	sqlDB, err := p.DB.DB()
 	if err == nil && sqlDB != nil {
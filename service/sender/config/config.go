@@ -2,6 +2,9 @@ package config
 
 import (
 	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/common/rpcpool"
+	"github.com/bnb-chain/zkbnb/service/sender/signer"
 )
 
 type Config struct {
@@ -13,9 +16,29 @@ type Config struct {
 		MaxWaitingTime          int64
 		MaxBlockCount           int
 		ConfirmBlocksCount      uint64
-		Sk                      string
-		GasLimit                uint64
-		GasPrice                uint64
+		// Deprecated: the plaintext private key, use Signer instead.
+		//nolint:staticcheck
+		Sk string `json:",optional"`
+		// The ceiling of the estimated gas limit of rollup txs.
+		GasLimit uint64
+		GasPrice uint64
+		// The percentage added to the estimated gas of rollup txs.
+		//nolint:staticcheck
+		GasLimitBuffer int64 `json:",optional"`
+		// The ceiling of the gas price in wei, 0 means no ceiling.
+		//nolint:staticcheck
+		MaxGasPrice uint64 `json:",optional"`
+		// The seconds to wait for a tx to be mined before it is sent again with a bumped gas price.
+		//nolint:staticcheck
+		GasPriceBumpInterval int64 `json:",optional"`
+		// The percentage by which the gas price is bumped, it should be at least 10.
+		//nolint:staticcheck
+		GasPriceBumpPercent int64 `json:",optional"`
+		// The sys config value of NetworkRPCSysConfigName can be a comma separated list of endpoints.
+		//nolint:staticcheck
+		RPCPool rpcpool.Config `json:",optional"`
 	}
+	//nolint:staticcheck
+	Signer  signer.Config `json:",optional"`
 	LogConf logx.LogConf
 }
@@ -0,0 +1,207 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+const (
+	defaultGasPriceBumpInterval = 60
+	defaultGasPriceBumpPercent  = 12
+	// Nodes reject a replacement tx unless its gas price is at least 10% higher.
+	minGasPriceBumpPercent = 10
+)
+
+// gasPrice returns the gas price for a new rollup tx, either the configured fixed price
+// or the price suggested by the node, capped by the configured ceiling.
+func (s *Sender) gasPrice(cli *rpc.ProviderClient) (*big.Int, error) {
+	var gasPrice *big.Int
+	if s.config.ChainConfig.GasPrice > 0 {
+		gasPrice = new(big.Int).SetUint64(s.config.ChainConfig.GasPrice)
+	} else {
+		var err error
+		gasPrice, err = cli.SuggestGasPrice(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch gas price: %v", err)
+		}
+	}
+	maxGasPrice := s.maxGasPrice()
+	if maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+		logx.Infof("suggested gas price %s exceeds the ceiling, use %s instead", gasPrice, maxGasPrice)
+		gasPrice = maxGasPrice
+	}
+	return gasPrice, nil
+}
+
+func (s *Sender) maxGasPrice() *big.Int {
+	if s.config.ChainConfig.MaxGasPrice == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(s.config.ChainConfig.MaxGasPrice)
+}
+
+func (s *Sender) gasPriceBumpInterval() time.Duration {
+	interval := s.config.ChainConfig.GasPriceBumpInterval
+	if interval <= 0 {
+		interval = defaultGasPriceBumpInterval
+	}
+	return time.Duration(interval) * time.Second
+}
+
+func (s *Sender) gasPriceBumpPercent() int64 {
+	percent := s.config.ChainConfig.GasPriceBumpPercent
+	if percent < minGasPriceBumpPercent {
+		percent = defaultGasPriceBumpPercent
+	}
+	return percent
+}
+
+// bumpGasPrice returns the gas price to replace a tx priced at oldPrice. The new price is
+// bumpPercent higher than the old one, or the market price if that has risen further. It
+// returns false if the ceiling does not leave room for a replacement accepted by the nodes.
+func bumpGasPrice(oldPrice, marketPrice, maxPrice *big.Int, bumpPercent int64) (*big.Int, bool) {
+	newPrice := new(big.Int).Mul(oldPrice, big.NewInt(100+bumpPercent))
+	newPrice.Div(newPrice, big.NewInt(100))
+	if newPrice.Cmp(oldPrice) <= 0 {
+		newPrice.Add(oldPrice, big.NewInt(1))
+	}
+	if marketPrice != nil && marketPrice.Cmp(newPrice) > 0 {
+		newPrice.Set(marketPrice)
+	}
+	if maxPrice != nil && newPrice.Cmp(maxPrice) > 0 {
+		newPrice.Set(maxPrice)
+	}
+
+	minPrice := new(big.Int).Mul(oldPrice, big.NewInt(100+minGasPriceBumpPercent))
+	minPrice.Div(minPrice, big.NewInt(100))
+	if newPrice.Cmp(minPrice) < 0 || newPrice.Cmp(oldPrice) <= 0 {
+		return nil, false
+	}
+	return newPrice, true
+}
+
+// groupRollupTxAttempts groups the pending rollup txs by type and nonce, each group holds
+// the attempts to send the same rollup tx ordered from the first to the latest one.
+func groupRollupTxAttempts(pendingTxs []*l1rolluptx.L1RollupTx) [][]*l1rolluptx.L1RollupTx {
+	type attemptKey struct {
+		txType uint8
+		nonce  uint64
+	}
+	var (
+		groups  [][]*l1rolluptx.L1RollupTx
+		indexes = make(map[attemptKey]int)
+	)
+	for _, pendingTx := range pendingTxs {
+		key := attemptKey{txType: pendingTx.TxType, nonce: pendingTx.Nonce}
+		index, ok := indexes[key]
+		if !ok {
+			index = len(groups)
+			indexes[key] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], pendingTx)
+	}
+	for _, attempts := range groups {
+		sort.Slice(attempts, func(i, j int) bool {
+			return attempts[i].ID < attempts[j].ID
+		})
+	}
+	return groups
+}
+
+// handleUnminedTx re-broadcasts the latest attempt of a rollup tx with the same nonce and a
+// bumped gas price once it has waited for the bump interval. The attempts are deleted if the
+// node does not know the tx any longer, so that the blocks are sent again.
+func (s *Sender) handleUnminedTx(cli *rpc.ProviderClient, attempts []*l1rolluptx.L1RollupTx) {
+	latestTx := attempts[len(attempts)-1]
+	sentTx, _, err := cli.GetTransactionByHash(latestTx.L1TxHash)
+	if err != nil {
+		logx.Errorf("query transaction %s failed, err: %v", latestTx.L1TxHash, err)
+		if time.Now().After(latestTx.UpdatedAt.Add(time.Duration(s.config.ChainConfig.MaxWaitingTime) * time.Second)) {
+			for _, attempt := range attempts {
+				// No need to check the response, do best effort.
+				logx.Infof("delete timeout l1 rollup tx, tx_hash=%s", attempt.L1TxHash)
+				//nolint:errcheck
+				s.l1RollupTxModel.DeleteL1RollupTx(attempt)
+			}
+		}
+		return
+	}
+	if time.Now().Before(latestTx.CreatedAt.Add(s.gasPriceBumpInterval())) {
+		return
+	}
+
+	replaceTx, err := s.replaceRollupTx(cli, latestTx, sentTx)
+	if err != nil {
+		logx.Errorf("failed to replace l1 rollup tx %s, err: %v", latestTx.L1TxHash, err)
+		return
+	}
+	logx.Infof("l1 rollup tx %s is not mined in time, replaced by %s with gas price %s",
+		latestTx.L1TxHash, replaceTx.L1TxHash, replaceTx.GasPrice)
+}
+
+func (s *Sender) replaceRollupTx(cli *rpc.ProviderClient, latestTx *l1rolluptx.L1RollupTx, sentTx *ethTypes.Transaction) (*l1rolluptx.L1RollupTx, error) {
+	marketPrice, err := cli.SuggestGasPrice(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch gas price: %v", err)
+	}
+	gasPrice, ok := bumpGasPrice(sentTx.GasPrice(), marketPrice, s.maxGasPrice(), s.gasPriceBumpPercent())
+	if !ok {
+		return nil, fmt.Errorf("gas price %s reaches the ceiling %d", sentTx.GasPrice(), s.config.ChainConfig.MaxGasPrice)
+	}
+
+	signedTx, err := s.signer.SignTx(ethTypes.NewTx(&ethTypes.LegacyTx{
+		Nonce:    sentTx.Nonce(),
+		GasPrice: gasPrice,
+		Gas:      sentTx.Gas(),
+		To:       sentTx.To(),
+		Value:    sentTx.Value(),
+		Data:     sentTx.Data(),
+	}), s.chainId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tx: %v", err)
+	}
+	err = cli.SendTransaction(context.Background(), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %v", err)
+	}
+
+	replaceTx := &l1rolluptx.L1RollupTx{
+		L1TxHash:      signedTx.Hash().String(),
+		TxStatus:      l1rolluptx.StatusPending,
+		TxType:        latestTx.TxType,
+		L2BlockHeight: latestTx.L2BlockHeight,
+		Nonce:         signedTx.Nonce(),
+		GasPrice:      gasPrice.String(),
+	}
+	err = s.l1RollupTxModel.CreateL1RollupTx(replaceTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tx in database, err: %v", err)
+	}
+	return replaceTx, nil
+}
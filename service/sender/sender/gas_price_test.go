@@ -0,0 +1,67 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+func TestBumpGasPrice(t *testing.T) {
+	gwei := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9))
+	}
+
+	// bumped by percent
+	price, ok := bumpGasPrice(gwei(10), gwei(5), nil, 12)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(11.2e9), price)
+
+	// follows the market price
+	price, ok = bumpGasPrice(gwei(10), gwei(20), gwei(100), 12)
+	assert.True(t, ok)
+	assert.Equal(t, gwei(20), price)
+
+	// capped by the ceiling
+	price, ok = bumpGasPrice(gwei(10), gwei(20), gwei(15), 12)
+	assert.True(t, ok)
+	assert.Equal(t, gwei(15), price)
+
+	// no room for a valid replacement
+	_, ok = bumpGasPrice(gwei(10), gwei(20), big.NewInt(10.5e9), 12)
+	assert.False(t, ok)
+}
+
+func TestGroupRollupTxAttempts(t *testing.T) {
+	newTx := func(id uint, txType uint8, nonce uint64) *l1rolluptx.L1RollupTx {
+		return &l1rolluptx.L1RollupTx{Model: gorm.Model{ID: id}, TxType: txType, Nonce: nonce}
+	}
+	groups := groupRollupTxAttempts([]*l1rolluptx.L1RollupTx{
+		newTx(3, l1rolluptx.TxTypeCommit, 7),
+		newTx(2, l1rolluptx.TxTypeVerifyAndExecute, 6),
+		newTx(1, l1rolluptx.TxTypeCommit, 7),
+	})
+	assert.Len(t, groups, 2)
+	assert.Equal(t, []uint{1, 3}, []uint{groups[0][0].ID, groups[0][1].ID})
+	assert.Len(t, groups[1], 1)
+	assert.Equal(t, uint(2), groups[1][0].ID)
+}
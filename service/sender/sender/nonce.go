@@ -0,0 +1,93 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// nextNonce returns the nonce for a new rollup tx. The sender account nonce is tracked by the
+// pending rollup txs instead of the pending nonce of the node, so that it survives restarts and
+// the nonce of a dropped tx is reused. The caller must hold sendLock until the tx is recorded,
+// so that commit and verify txs never share a nonce.
+func (s *Sender) nextNonce(cli *rpc.ProviderClient) (uint64, error) {
+	confirmedNonce, err := cli.NonceAt(context.Background(), s.signer.Address(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce of %s, err: %v", s.signer.Address().Hex(), err)
+	}
+	pendingTxs, err := s.l1RollupTxModel.GetL1RollupTxsByStatus(l1rolluptx.StatusPending)
+	if err != nil && err != types.DbErrNotFound {
+		return 0, fmt.Errorf("failed to get pending txs, err: %v", err)
+	}
+
+	usedNonces := make(map[uint64]bool, len(pendingTxs))
+	for _, pendingTx := range pendingTxs {
+		err = s.fillRollupTxNonce(cli, pendingTx)
+		if err != nil {
+			return 0, err
+		}
+		usedNonces[pendingTx.Nonce] = true
+	}
+	nonce := confirmedNonce
+	for usedNonces[nonce] {
+		nonce++
+	}
+
+	pendingNonce, err := cli.PendingNonceAt(context.Background(), s.signer.Address())
+	if err == nil && pendingNonce > nonce {
+		logx.Errorf("pending nonce %d of %s is higher than the next nonce %d, the account sends txs not tracked by the sender",
+			pendingNonce, s.signer.Address().Hex(), nonce)
+	}
+	return nonce, nil
+}
+
+// fillRollupTxNonce records the nonce and gas price of a rollup tx sent by former versions,
+// which do not track them.
+func (s *Sender) fillRollupTxNonce(cli *rpc.ProviderClient, rollupTx *l1rolluptx.L1RollupTx) error {
+	if rollupTx.GasPrice != "" {
+		return nil
+	}
+	sentTx, _, err := cli.GetTransactionByHash(rollupTx.L1TxHash)
+	if err != nil {
+		return fmt.Errorf("failed to get tx %s, err: %v", rollupTx.L1TxHash, err)
+	}
+	rollupTx.Nonce = sentTx.Nonce()
+	rollupTx.GasPrice = sentTx.GasPrice().String()
+	err = s.l1RollupTxModel.UpdateL1RollupTxsInTransact(s.db, []*l1rolluptx.L1RollupTx{rollupTx})
+	if err != nil {
+		return fmt.Errorf("failed to update tx in database, err: %v", err)
+	}
+	return nil
+}
+
+// isNonceUsedByOtherTx reports whether the nonce of a rollup tx, none of whose attempts is
+// mined, is used by a finalized tx of the account. finalizedNonce is the nonce of the account
+// at the height which is the confirmation depth behind the latest one.
+func isNonceUsedByOtherTx(rollupTx *l1rolluptx.L1RollupTx, finalizedNonce uint64) bool {
+	// The nonce of txs sent by former versions is unknown.
+	if rollupTx.GasPrice == "" {
+		return false
+	}
+	return rollupTx.Nonce < finalizedNonce
+}
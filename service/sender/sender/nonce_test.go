@@ -0,0 +1,38 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+func TestIsNonceUsedByOtherTx(t *testing.T) {
+	rollupTx := &l1rolluptx.L1RollupTx{Nonce: 5, GasPrice: "1000000000"}
+
+	// the nonce is not used by a finalized tx yet
+	assert.False(t, isNonceUsedByOtherTx(rollupTx, 0))
+	assert.False(t, isNonceUsedByOtherTx(rollupTx, 5))
+	// a finalized tx uses the nonce
+	assert.True(t, isNonceUsedByOtherTx(rollupTx, 6))
+
+	// the nonce of txs sent by former versions is unknown
+	assert.False(t, isNonceUsedByOtherTx(&l1rolluptx.L1RollupTx{}, 6))
+}
@@ -22,8 +22,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"time"
+	"sync"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -32,12 +36,14 @@ import (
 	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
 	"github.com/bnb-chain/zkbnb/common/chain"
 	"github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/common/rpcpool"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
 	"github.com/bnb-chain/zkbnb/dao/proof"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	sconfig "github.com/bnb-chain/zkbnb/service/sender/config"
+	"github.com/bnb-chain/zkbnb/service/sender/signer"
 	"github.com/bnb-chain/zkbnb/types"
 )
 
@@ -45,9 +51,12 @@ type Sender struct {
 	config sconfig.Config
 
 	// Client
-	cli           *rpc.ProviderClient
-	authCli       *rpc.AuthClient
-	zkbnbInstance *zkbnb.ZkBNB
+	rpcPool       *rpcpool.Pool
+	signer        signer.Signer
+	chainId       *big.Int
+	rollupAddress common.Address
+	// sendLock serializes the nonce assignment of commit and verify txs
+	sendLock sync.Mutex
 
 	// Data access objects
 	db                   *gorm.DB
@@ -72,6 +81,13 @@ func NewSender(c sconfig.Config) *Sender {
 		sysConfigModel:       sysconfig.NewSysConfigModel(db),
 		proofModel:           proof.NewProofModel(db),
 	}
+	// add the columns tracking the nonce and l1 block of rollup txs to tables created
+	// by former versions
+	err = s.l1RollupTxModel.CreateL1RollupTxTable()
+	if err != nil {
+		logx.Severef("fatal error, cannot migrate l1 rollup tx table, err: %v", err)
+		panic(err)
+	}
 
 	l1RPCEndpoint, err := s.sysConfigModel.GetSysConfigByName(c.ChainConfig.NetworkRPCSysConfigName)
 	if err != nil {
@@ -86,31 +102,30 @@ func NewSender(c sconfig.Config) *Sender {
 		panic(err)
 	}
 
-	s.cli, err = rpc.NewClient(l1RPCEndpoint.Value)
+	s.rpcPool, err = rpcpool.NewPool(rpcpool.ParseEndpoints(l1RPCEndpoint.Value), c.ChainConfig.RPCPool)
 	if err != nil {
+		logx.Severef("fatal error, cannot create rpc pool, err: %v", err)
 		panic(err)
 	}
-	chainId, err := s.cli.ChainID(context.Background())
+	chainId, err := s.rpcPool.Client().ChainID(context.Background())
 	if err != nil {
 		panic(err)
 	}
-	s.authCli, err = rpc.NewAuthClient(c.ChainConfig.Sk, chainId)
-	if err != nil {
-		panic(err)
-	}
-	s.zkbnbInstance, err = zkbnb.LoadZkBNBInstance(s.cli, rollupAddress.Value)
+	s.chainId = chainId
+	s.signer, err = signer.NewSigner(c.Signer, c.ChainConfig.Sk)
 	if err != nil {
+		logx.Severef("fatal error, cannot create signer, err: %v", err)
 		panic(err)
 	}
+	s.rollupAddress = common.HexToAddress(rollupAddress.Value)
 	return s
 }
 
-func (s *Sender) CommitBlocks() (err error) {
-	var (
-		cli           = s.cli
-		authCli       = s.authCli
-		zkbnbInstance = s.zkbnbInstance
-	)
+func (s *Sender) CommitBlocks() error {
+	return s.rpcPool.Do(s.commitBlocks)
+}
+
+func (s *Sender) commitBlocks(cli *rpc.ProviderClient) (err error) {
 	pendingTx, err := s.l1RollupTxModel.GetLatestPendingTx(l1rolluptx.TxTypeCommit)
 	if err != nil && err != types.DbErrNotFound {
 		return err
@@ -152,33 +167,38 @@ func (s *Sender) CommitBlocks() (err error) {
 		lastStoredBlockInfo = chain.ConstructStoredBlockInfo(lastHandledBlockInfo)
 	}
 
-	var gasPrice *big.Int
-	if s.config.ChainConfig.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(s.config.ChainConfig.GasPrice))
-	} else {
-		gasPrice, err = s.cli.SuggestGasPrice(context.Background())
-		if err != nil {
-			logx.Errorf("failed to fetch gas price: %v", err)
-			return err
-		}
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	nonce, err := s.nextNonce(cli)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := s.gasPrice(cli)
+	if err != nil {
+		return err
 	}
+	gasLimit, err := s.simulateRollupTx(cli, MethodNameCommitBlocks, gasPrice, lastStoredBlockInfo, pendingCommitBlocks)
+	if err != nil {
+		return err
+	}
+	transactOpts := s.constructTransactOpts(nonce, gasPrice, gasLimit)
 
 	// commit blocks on-chain
-	txHash, err := zkbnb.CommitBlocks(
-		cli, authCli,
-		zkbnbInstance,
-		lastStoredBlockInfo,
-		pendingCommitBlocks,
-		gasPrice,
-		s.config.ChainConfig.GasLimit)
+	zkbnbTransactor, err := zkbnb.NewZkBNBTransactor(s.rollupAddress, cli)
 	if err != nil {
-		return fmt.Errorf("failed to send commit tx, errL %v:%s", err, txHash)
+		return err
+	}
+	sentTx, err := zkbnbTransactor.CommitBlocks(transactOpts, lastStoredBlockInfo, pendingCommitBlocks)
+	if err != nil {
+		return fmt.Errorf("failed to send commit tx, err: %v", err)
 	}
 	newRollupTx := &l1rolluptx.L1RollupTx{
-		L1TxHash:      txHash,
+		L1TxHash:      sentTx.Hash().String(),
 		TxStatus:      l1rolluptx.StatusPending,
 		TxType:        l1rolluptx.TxTypeCommit,
 		L2BlockHeight: int64(pendingCommitBlocks[len(pendingCommitBlocks)-1].BlockNumber),
+		Nonce:         sentTx.Nonce(),
+		GasPrice:      sentTx.GasPrice().String(),
 	}
 	err = s.l1RollupTxModel.CreateL1RollupTx(newRollupTx)
 	if err != nil {
@@ -188,7 +208,11 @@ func (s *Sender) CommitBlocks() (err error) {
 	return nil
 }
 
-func (s *Sender) UpdateSentTxs() (err error) {
+func (s *Sender) UpdateSentTxs() error {
+	return s.rpcPool.Do(s.updateSentTxs)
+}
+
+func (s *Sender) updateSentTxs(cli *rpc.ProviderClient) (err error) {
 	pendingTxs, err := s.l1RollupTxModel.GetL1RollupTxsByStatus(l1rolluptx.StatusPending)
 	if err != nil {
 		if err == types.DbErrNotFound {
@@ -197,35 +221,73 @@ func (s *Sender) UpdateSentTxs() (err error) {
 		return fmt.Errorf("failed to get pending txs, err: %v", err)
 	}
 
-	latestL1Height, err := s.cli.GetHeight()
+	latestL1Height, err := cli.GetHeight()
 	if err != nil {
 		return fmt.Errorf("failed to get l1 block height, err: %v", err)
 	}
+	// A tx is only taken as replaced once the tx using its nonce is as deep as the
+	// confirmation depth, so that a receipt missing on a lagging node does not count.
+	var finalizedNonce uint64
+	if latestL1Height > s.config.ChainConfig.ConfirmBlocksCount {
+		finalizedHeight := new(big.Int).SetUint64(latestL1Height - s.config.ChainConfig.ConfirmBlocksCount)
+		finalizedNonce, err = cli.NonceAt(context.Background(), s.signer.Address(), finalizedHeight)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce of %s, err: %v", s.signer.Address().Hex(), err)
+		}
+	}
+	for _, pendingTx := range pendingTxs {
+		err = s.fillRollupTxNonce(cli, pendingTx)
+		if err != nil {
+			logx.Errorf("failed to fill nonce of l1 rollup tx, err: %v", err)
+		}
+	}
 
 	var (
 		pendingUpdateRxs         []*l1rolluptx.L1RollupTx
 		pendingUpdateProofStatus = make(map[int64]int)
+		failedTxHashes           []string
 	)
-	for _, pendingTx := range pendingTxs {
-		txHash := pendingTx.L1TxHash
-		receipt, err := s.cli.GetTransactionReceipt(txHash)
-		if err != nil {
-			logx.Errorf("query transaction receipt %s failed, err: %v", txHash, err)
-			if time.Now().After(pendingTx.UpdatedAt.Add(time.Duration(s.config.ChainConfig.MaxWaitingTime) * time.Second)) {
-				// No need to check the response, do best effort.
-				logx.Infof("delete timeout l1 rollup tx, tx_hash=%s", pendingTx.L1TxHash)
-				//nolint:errcheck
-				s.l1RollupTxModel.DeleteL1RollupTx(pendingTx)
+	for _, attempts := range groupRollupTxAttempts(pendingTxs) {
+		// Any of the attempts may be mined, they share the same nonce.
+		var (
+			pendingTx *l1rolluptx.L1RollupTx
+			receipt   *ethTypes.Receipt
+			notMined  = true
+		)
+		for _, attempt := range attempts {
+			receipt, err = cli.GetTransactionReceipt(attempt.L1TxHash)
+			if err != nil {
+				logx.Errorf("query transaction receipt %s failed, err: %v", attempt.L1TxHash, err)
+				notMined = notMined && err == ethereum.NotFound
+				continue
+			}
+			pendingTx = attempt
+			break
+		}
+		if pendingTx == nil {
+			if notMined && isNonceUsedByOtherTx(attempts[0], finalizedNonce) {
+				// The nonce is used by a tx which is not tracked, the blocks need to be sent again.
+				logx.Errorf("nonce %d of l1 rollup tx %s is used by another tx, mark the tx replaced",
+					attempts[0].Nonce, attempts[len(attempts)-1].L1TxHash)
+				for _, attempt := range attempts {
+					attempt.TxStatus = l1rolluptx.StatusReplaced
+					pendingUpdateRxs = append(pendingUpdateRxs, attempt)
+				}
+				continue
 			}
+			s.handleUnminedTx(cli, attempts)
 			continue
 		}
+		txHash := pendingTx.L1TxHash
 		if receipt.Status == 0 {
-			// Should direct mark tx deleted
-			logx.Infof("delete timeout l1 rollup tx, tx_hash=%s", pendingTx.L1TxHash)
-			//nolint:errcheck
-			s.l1RollupTxModel.DeleteL1RollupTx(pendingTx)
-			// It is critical to have any failed transactions
-			panic(fmt.Sprintf("unexpected failed tx: %v", txHash))
+			// It is critical to have any failed transactions, the tx is marked failed so that
+			// its blocks are sent again by a new tx instead of waiting for it forever.
+			logx.Severef("l1 rollup tx %s of block %d failed, revert reason: %s", txHash, pendingTx.L2BlockHeight,
+				s.failedTxRevertReason(cli, txHash, receipt.BlockNumber))
+			pendingUpdateRxs = append(pendingUpdateRxs,
+				markMinedAttempt(attempts, pendingTx, l1rolluptx.StatusFailed, receipt.BlockNumber.Int64())...)
+			failedTxHashes = append(failedTxHashes, txHash)
+			continue
 		}
 
 		// not finalized yet
@@ -255,8 +317,8 @@ func (s *Sender) UpdateSentTxs() (err error) {
 		}
 
 		if validTx {
-			pendingTx.TxStatus = l1rolluptx.StatusHandled
-			pendingUpdateRxs = append(pendingUpdateRxs, pendingTx)
+			pendingUpdateRxs = append(pendingUpdateRxs,
+				markMinedAttempt(attempts, pendingTx, l1rolluptx.StatusHandled, receipt.BlockNumber.Int64())...)
 		}
 	}
 
@@ -274,15 +336,31 @@ func (s *Sender) UpdateSentTxs() (err error) {
 	if err != nil {
 		return fmt.Errorf("failed to updte rollup txs, err:%v", err)
 	}
+	if len(failedTxHashes) > 0 {
+		return fmt.Errorf("l1 rollup txs %v failed", failedTxHashes)
+	}
 	return nil
 }
 
-func (s *Sender) VerifyAndExecuteBlocks() (err error) {
-	var (
-		cli           = s.cli
-		authCli       = s.authCli
-		zkbnbInstance = s.zkbnbInstance
-	)
+// markMinedAttempt sets the status of the mined attempt and marks the other attempts sharing its
+// nonce replaced, it returns all the attempts to be updated.
+func markMinedAttempt(attempts []*l1rolluptx.L1RollupTx, minedTx *l1rolluptx.L1RollupTx,
+	status int, l1BlockHeight int64) []*l1rolluptx.L1RollupTx {
+	minedTx.TxStatus = status
+	minedTx.L1BlockHeight = l1BlockHeight
+	for _, attempt := range attempts {
+		if attempt != minedTx {
+			attempt.TxStatus = l1rolluptx.StatusReplaced
+		}
+	}
+	return attempts
+}
+
+func (s *Sender) VerifyAndExecuteBlocks() error {
+	return s.rpcPool.Do(s.verifyAndExecuteBlocks)
+}
+
+func (s *Sender) verifyAndExecuteBlocks(cli *rpc.ProviderClient) (err error) {
 	pendingTx, err := s.l1RollupTxModel.GetLatestPendingTx(l1rolluptx.TxTypeVerifyAndExecute)
 	if err != nil && err != types.DbErrNotFound {
 		return err
@@ -340,29 +418,39 @@ func (s *Sender) VerifyAndExecuteBlocks() (err error) {
 		proofs = append(proofs, proofInfo.C[:]...)
 	}
 
-	var gasPrice *big.Int
-	if s.config.ChainConfig.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(s.config.ChainConfig.GasPrice))
-	} else {
-		gasPrice, err = s.cli.SuggestGasPrice(context.Background())
-		if err != nil {
-			logx.Errorf("failed to fetch gas price: %v", err)
-			return err
-		}
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	nonce, err := s.nextNonce(cli)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := s.gasPrice(cli)
+	if err != nil {
+		return err
+	}
+	gasLimit, err := s.simulateRollupTx(cli, MethodNameVerifyAndExecuteBlocks, gasPrice, pendingVerifyAndExecuteBlocks, proofs)
+	if err != nil {
+		return err
 	}
+	transactOpts := s.constructTransactOpts(nonce, gasPrice, gasLimit)
 
 	// Verify blocks on-chain
-	txHash, err := zkbnb.VerifyAndExecuteBlocks(cli, authCli, zkbnbInstance,
-		pendingVerifyAndExecuteBlocks, proofs, gasPrice, s.config.ChainConfig.GasLimit)
+	zkbnbTransactor, err := zkbnb.NewZkBNBTransactor(s.rollupAddress, cli)
+	if err != nil {
+		return err
+	}
+	sentTx, err := zkbnbTransactor.VerifyAndExecuteBlocks(transactOpts, pendingVerifyAndExecuteBlocks, proofs)
 	if err != nil {
-		return fmt.Errorf("failed to send verify tx: %v:%s", err, txHash)
+		return fmt.Errorf("failed to send verify tx: %v", err)
 	}
 
 	newRollupTx := &l1rolluptx.L1RollupTx{
-		L1TxHash:      txHash,
+		L1TxHash:      sentTx.Hash().String(),
 		TxStatus:      l1rolluptx.StatusPending,
 		TxType:        l1rolluptx.TxTypeVerifyAndExecute,
 		L2BlockHeight: int64(pendingVerifyAndExecuteBlocks[len(pendingVerifyAndExecuteBlocks)-1].BlockHeader.BlockNumber),
+		Nonce:         sentTx.Nonce(),
+		GasPrice:      sentTx.GasPrice().String(),
 	}
 	err = s.l1RollupTxModel.CreateL1RollupTx(newRollupTx)
 	if err != nil {
@@ -372,7 +460,27 @@ func (s *Sender) VerifyAndExecuteBlocks() (err error) {
 	return nil
 }
 
+func (s *Sender) constructTransactOpts(nonce uint64, gasPrice *big.Int, gasLimit uint64) *bind.TransactOpts {
+	from := s.signer.Address()
+	transactOpts := &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.signer.SignTx(tx, s.chainId)
+		},
+		Nonce:    new(big.Int).SetUint64(nonce),
+		GasPrice: gasPrice,
+		GasLimit: gasLimit,
+		Value:    big.NewInt(0),
+		Context:  context.Background(),
+	}
+	return transactOpts
+}
+
 func (s *Sender) Shutdown() {
+	s.rpcPool.Stop()
 	sqlDB, err := s.db.DB()
 	if err == nil && sqlDB != nil {
 		err = sqlDB.Close()
@@ -0,0 +1,48 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+func TestMarkMinedAttempt(t *testing.T) {
+	attempts := []*l1rolluptx.L1RollupTx{
+		{Model: gorm.Model{ID: 1}, TxStatus: l1rolluptx.StatusPending, Nonce: 7},
+		{Model: gorm.Model{ID: 2}, TxStatus: l1rolluptx.StatusPending, Nonce: 7},
+		{Model: gorm.Model{ID: 3}, TxStatus: l1rolluptx.StatusPending, Nonce: 7},
+	}
+
+	// a reverted attempt is kept as failed, so the blocks can be sent again
+	updated := markMinedAttempt(attempts, attempts[1], l1rolluptx.StatusFailed, 120)
+	assert.Len(t, updated, 3)
+	assert.Equal(t, l1rolluptx.StatusReplaced, attempts[0].TxStatus)
+	assert.Equal(t, l1rolluptx.StatusFailed, attempts[1].TxStatus)
+	assert.Equal(t, int64(120), attempts[1].L1BlockHeight)
+	assert.Equal(t, l1rolluptx.StatusReplaced, attempts[2].TxStatus)
+	assert.Equal(t, int64(0), attempts[2].L1BlockHeight)
+
+	updated = markMinedAttempt(attempts[2:], attempts[2], l1rolluptx.StatusHandled, 121)
+	assert.Len(t, updated, 1)
+	assert.Equal(t, l1rolluptx.StatusHandled, attempts[2].TxStatus)
+	assert.Equal(t, int64(121), attempts[2].L1BlockHeight)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethRpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+)
+
+const (
+	MethodNameCommitBlocks           = "commitBlocks"
+	MethodNameVerifyAndExecuteBlocks = "verifyAndExecuteBlocks"
+
+	defaultGasLimitBuffer = 20
+)
+
+// simulateRollupTx dry-runs the rollup call with eth_call and estimates its gas limit, so that
+// a call which would revert on chain is reported with its revert reason instead of being sent.
+// The estimated gas is raised by the configured buffer and capped by the configured gas limit.
+func (s *Sender) simulateRollupTx(cli *rpc.ProviderClient, method string, gasPrice *big.Int, args ...interface{}) (uint64, error) {
+	data, err := ZkBNBContractAbi.Pack(method, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack %s call, err: %v", method, err)
+	}
+	msg := ethereum.CallMsg{
+		From:     s.signer.Address(),
+		To:       &s.rollupAddress,
+		GasPrice: gasPrice,
+		Data:     data,
+	}
+	_, err = cli.CallContract(context.Background(), msg, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s call reverted: %s", method, revertReason(err))
+	}
+	estimatedGas, err := cli.EstimateGas(context.Background(), msg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas of %s call: %s", method, revertReason(err))
+	}
+
+	buffer := s.config.ChainConfig.GasLimitBuffer
+	if buffer <= 0 {
+		buffer = defaultGasLimitBuffer
+	}
+	gasLimit := estimatedGas * uint64(100+buffer) / 100
+	maxGasLimit := s.config.ChainConfig.GasLimit
+	if maxGasLimit > 0 && gasLimit > maxGasLimit {
+		if estimatedGas > maxGasLimit {
+			return 0, fmt.Errorf("estimated gas %d of %s call exceeds the gas limit %d", estimatedGas, method, maxGasLimit)
+		}
+		gasLimit = maxGasLimit
+	}
+	return gasLimit, nil
+}
+
+// failedTxRevertReason replays a failed rollup tx on top of the parent of the block it is
+// mined in to find out why it reverted.
+func (s *Sender) failedTxRevertReason(cli *rpc.ProviderClient, txHash string, blockNumber *big.Int) string {
+	sentTx, _, err := cli.GetTransactionByHash(txHash)
+	if err != nil {
+		return fmt.Sprintf("unknown, failed to get tx: %v", err)
+	}
+	msg := ethereum.CallMsg{
+		From:     s.signer.Address(),
+		To:       sentTx.To(),
+		Gas:      sentTx.Gas(),
+		GasPrice: sentTx.GasPrice(),
+		Value:    sentTx.Value(),
+		Data:     sentTx.Data(),
+	}
+	_, err = cli.CallContract(context.Background(), msg, new(big.Int).Sub(blockNumber, big.NewInt(1)))
+	if err != nil {
+		return revertReason(err)
+	}
+	return "unknown, the replayed call succeeds"
+}
+
+// revertReason decodes the reason string of a reverted call from the error data returned by
+// the node, it falls back to the error message if there is none.
+func revertReason(err error) string {
+	dataErr, ok := err.(ethRpc.DataError)
+	if !ok {
+		return err.Error()
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	reason, unpackErr := abi.UnpackRevert(common.FromHex(hexData))
+	if unpackErr != nil {
+		return err.Error()
+	}
+	return reason
+}
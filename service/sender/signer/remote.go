@@ -0,0 +1,267 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signer
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// The remote signing protocol, a signing service serves
+//
+//	GET  <url>/address: responds {"address": "0x..."}
+//	POST <url>/sign_tx: requests {"chain_id": "97", "tx": "0x..."}, responds {"signed_tx": "0x..."}
+//
+// where the txs are in their binary encoding. Every request carries the token shared with the
+// service in the "Authorization: Bearer <token>" header. Failures are responded with a non 2xx
+// status code and {"error": "..."}.
+const (
+	addressPath = "/address"
+	signTxPath  = "/sign_tx"
+
+	remoteSignerTimeout = 10 * time.Second
+)
+
+type AddressResponse struct {
+	Address string `json:"address"`
+}
+
+type SignTxRequest struct {
+	ChainId string `json:"chain_id"`
+	Tx      string `json:"tx"`
+}
+
+type SignTxResponse struct {
+	SignedTx string `json:"signed_tx"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type remoteSigner struct {
+	url     string
+	token   string
+	client  *http.Client
+	address common.Address
+}
+
+// NewRemoteSigner creates a signer which delegates signing to the signing service at url, token
+// is the bearer token shared with the service.
+func NewRemoteSigner(url, token string) (Signer, error) {
+	if url == "" {
+		return nil, errors.New("remote signer url is not configured")
+	}
+	if token == "" {
+		return nil, errors.New("remote signer token is not configured")
+	}
+	s := &remoteSigner{
+		url:    strings.TrimRight(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: remoteSignerTimeout},
+	}
+	var resp AddressResponse
+	err := s.do(http.MethodGet, addressPath, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address from remote signer: %v", err)
+	}
+	if !common.IsHexAddress(resp.Address) {
+		return nil, fmt.Errorf("invalid address from remote signer: %s", resp.Address)
+	}
+	s.address = common.HexToAddress(resp.Address)
+	return s, nil
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *remoteSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var resp SignTxResponse
+	err = s.do(http.MethodPost, signTxPath, &SignTxRequest{
+		ChainId: chainId.String(),
+		Tx:      hexutil.Encode(txBytes),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tx by remote signer: %v", err)
+	}
+	signedTxBytes, err := hexutil.Decode(resp.SignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed tx from remote signer: %v", err)
+	}
+	signedTx := new(types.Transaction)
+	err = signedTx.UnmarshalBinary(signedTxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed tx from remote signer: %v", err)
+	}
+
+	// Never trust the signing service to sign what is asked for.
+	txSigner := types.LatestSignerForChainID(chainId)
+	if txSigner.Hash(signedTx) != txSigner.Hash(tx) {
+		return nil, errors.New("remote signer signed a different tx")
+	}
+	from, err := types.Sender(txSigner, signedTx)
+	if err != nil || from != s.address {
+		return nil, errors.New("remote signer signed the tx with a different account")
+	}
+	return signedTx, nil
+}
+
+func (s *remoteSigner) do(method, path string, reqBody, respBody interface{}) error {
+	var body io.Reader
+	if reqBody != nil {
+		bz, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(bz)
+	}
+	req, err := http.NewRequest(method, s.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var errResp ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("status code %d: %s", resp.StatusCode, errResp.Error)
+		}
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(respBody)
+}
+
+// NewRemoteSignerHandler serves the remote signing protocol with the given signer, it can
+// stand in for a signing service. Only the requests carrying the token are served, and only
+// the txs sent to the rollup contract are signed.
+func NewRemoteSignerHandler(signer Signer, token string, rollupAddress common.Address) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(addressPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJson(w, http.StatusMethodNotAllowed, &ErrorResponse{Error: "method not allowed"})
+			return
+		}
+		writeJson(w, http.StatusOK, &AddressResponse{Address: signer.Address().Hex()})
+	})
+	mux.HandleFunc(signTxPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJson(w, http.StatusMethodNotAllowed, &ErrorResponse{Error: "method not allowed"})
+			return
+		}
+		var req SignTxRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, &ErrorResponse{Error: "invalid request"})
+			return
+		}
+		chainId, ok := new(big.Int).SetString(req.ChainId, 10)
+		if !ok {
+			writeJson(w, http.StatusBadRequest, &ErrorResponse{Error: "invalid chain id"})
+			return
+		}
+		txBytes, err := hexutil.Decode(req.Tx)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, &ErrorResponse{Error: "invalid tx"})
+			return
+		}
+		tx := new(types.Transaction)
+		err = tx.UnmarshalBinary(txBytes)
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, &ErrorResponse{Error: "invalid tx"})
+			return
+		}
+		if tx.To() == nil || *tx.To() != rollupAddress {
+			logx.Errorf("refuse to sign tx to %v, only txs to the rollup contract %s are signed", tx.To(), rollupAddress.Hex())
+			writeJson(w, http.StatusForbidden, &ErrorResponse{Error: "tx is not sent to the rollup contract"})
+			return
+		}
+		signedTx, err := signer.SignTx(tx, chainId)
+		if err != nil {
+			writeJson(w, http.StatusInternalServerError, &ErrorResponse{Error: err.Error()})
+			return
+		}
+		signedTxBytes, err := signedTx.MarshalBinary()
+		if err != nil {
+			writeJson(w, http.StatusInternalServerError, &ErrorResponse{Error: err.Error()})
+			return
+		}
+		logx.Infof("sign tx %s, nonce: %d", signedTx.Hash().Hex(), signedTx.Nonce())
+		writeJson(w, http.StatusOK, &SignTxResponse{SignedTx: hexutil.Encode(signedTxBytes)})
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("Bearer "+token)) != 1 {
+			writeJson(w, http.StatusUnauthorized, &ErrorResponse{Error: "unauthorized"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ServeRemoteSigner serves the remote signing protocol on addr with the key of a keystore file,
+// the bearer token is read from tokenFile and only the txs to rollupAddress are signed.
+func ServeRemoteSigner(addr, keystoreFile, passwordFile, tokenFile, rollupAddress string) error {
+	if !common.IsHexAddress(rollupAddress) {
+		return fmt.Errorf("invalid rollup contract address: %s", rollupAddress)
+	}
+	tokenBytes, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read token file: %v", err)
+	}
+	token := strings.TrimRight(string(tokenBytes), "\r\n")
+	if token == "" {
+		return errors.New("token file is empty")
+	}
+	signer, err := NewKeystoreSigner(keystoreFile, passwordFile)
+	if err != nil {
+		return err
+	}
+	logx.Infof("remote signer of %s is listening on %s", signer.Address().Hex(), addr)
+	return http.ListenAndServe(addr, NewRemoteSignerHandler(signer, token, common.HexToAddress(rollupAddress)))
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	//nolint:errcheck
+	json.NewEncoder(w).Encode(resp)
+}
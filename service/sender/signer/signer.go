@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	TypePrivateKey = "privateKey"
+	TypeKeystore   = "keystore"
+	TypeRemote     = "remote"
+)
+
+// Signer signs the l1 txs of the sender account.
+type Signer interface {
+	// Address returns the address of the sender account.
+	Address() common.Address
+	// SignTx signs the tx for the given chain.
+	SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+}
+
+type Config struct {
+	// Type of the signer, one of privateKey, keystore and remote. The private key signer
+	// reads the plaintext key from ChainConfig.Sk, it is the default for compatibility.
+	//nolint:staticcheck
+	Type string `json:",optional"`
+	// The encrypted keystore file and the file containing its password, used by the keystore signer.
+	//nolint:staticcheck
+	KeystoreFile string `json:",optional"`
+	//nolint:staticcheck
+	KeystorePasswordFile string `json:",optional"`
+	// The url of the signing service and the bearer token shared with it, used by the remote signer.
+	//nolint:staticcheck
+	RemoteUrl string `json:",optional"`
+	//nolint:staticcheck
+	RemoteToken string `json:",optional"`
+}
+
+// NewSigner creates the signer selected by the config, sk is the legacy plaintext private key.
+func NewSigner(c Config, sk string) (Signer, error) {
+	switch c.Type {
+	case "", TypePrivateKey:
+		if sk == "" {
+			return nil, errors.New("private key is not configured")
+		}
+		logx.Info("the private key signer is used, it is recommended to use the keystore or remote signer")
+		return NewPrivateKeySigner(sk)
+	case TypeKeystore:
+		return NewKeystoreSigner(c.KeystoreFile, c.KeystorePasswordFile)
+	case TypeRemote:
+		return NewRemoteSigner(c.RemoteUrl, c.RemoteToken)
+	default:
+		return nil, fmt.Errorf("unknown signer type: %s", c.Type)
+	}
+}
+
+type privateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewPrivateKeySigner(sk string) (Signer, error) {
+	privateKey, err := crypto.HexToECDSA(sk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return newPrivateKeySigner(privateKey), nil
+}
+
+func newPrivateKeySigner(privateKey *ecdsa.PrivateKey) Signer {
+	return &privateKeySigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *privateKeySigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainId), s.privateKey)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signer
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSk    = "107f9d2a50ce2d8337e0c5220574e9fcf2bf60002da5acf07718f4d531ea3faa"
+	testToken = "shared-secret"
+)
+
+var testRollupAddress = common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+func TestSigners(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(testSk)
+	assert.NoError(t, err)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// keystore signer
+	dir := t.TempDir()
+	account, err := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP).
+		ImportECDSA(privateKey, "secret")
+	assert.NoError(t, err)
+	keystoreFile := account.URL.Path
+	passwordFile := filepath.Join(dir, "password")
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("secret\n"), 0600))
+
+	keystoreSigner, err := NewSigner(Config{
+		Type:                 TypeKeystore,
+		KeystoreFile:         keystoreFile,
+		KeystorePasswordFile: passwordFile,
+	}, "")
+	assert.NoError(t, err)
+	assertSignTx(t, keystoreSigner, address)
+
+	// remote signer served by the keystore signer
+	server := httptest.NewServer(NewRemoteSignerHandler(keystoreSigner, testToken, testRollupAddress))
+	defer server.Close()
+	remoteSigner, err := NewSigner(Config{Type: TypeRemote, RemoteUrl: server.URL, RemoteToken: testToken}, "")
+	assert.NoError(t, err)
+	assertSignTx(t, remoteSigner, address)
+
+	_, err = NewSigner(Config{Type: TypeRemote, RemoteUrl: server.URL}, "")
+	assert.Error(t, err)
+
+	// private key signer
+	privateKeySigner, err := NewSigner(Config{}, testSk)
+	assert.NoError(t, err)
+	assertSignTx(t, privateKeySigner, address)
+
+	_, err = NewSigner(Config{Type: "unknown"}, testSk)
+	assert.Error(t, err)
+}
+
+func assertSignTx(t *testing.T, signer Signer, address common.Address) {
+	assert.Equal(t, address, signer.Address())
+
+	chainId := big.NewInt(97)
+	tx := newTestTx(testRollupAddress)
+	signedTx, err := signer.SignTx(tx, chainId)
+	assert.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+	assert.NoError(t, err)
+	assert.Equal(t, address, from)
+	assert.Equal(t, tx.Nonce(), signedTx.Nonce())
+	assert.Equal(t, tx.Data(), signedTx.Data())
+}
+
+func TestRemoteSignerHandler(t *testing.T) {
+	privateKeySigner, err := NewPrivateKeySigner(testSk)
+	assert.NoError(t, err)
+	server := httptest.NewServer(NewRemoteSignerHandler(privateKeySigner, testToken, testRollupAddress))
+	defer server.Close()
+
+	// the requests without the shared token are refused
+	_, err = NewRemoteSigner(server.URL, "wrong-token")
+	assert.ErrorContains(t, err, "status code 401")
+	resp, err := http.Get(server.URL + addressPath)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// the txs not sent to the rollup contract are refused
+	remoteSigner, err := NewRemoteSigner(server.URL, testToken)
+	assert.NoError(t, err)
+	_, err = remoteSigner.SignTx(newTestTx(common.HexToAddress("0x0000000000000000000000000000000000000002")), big.NewInt(97))
+	assert.ErrorContains(t, err, "status code 403")
+	_, err = remoteSigner.SignTx(types.NewTx(&types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(1e10), Gas: 21000}), big.NewInt(97))
+	assert.ErrorContains(t, err, "status code 403")
+	_, err = remoteSigner.SignTx(newTestTx(testRollupAddress), big.NewInt(97))
+	assert.NoError(t, err)
+}
+
+func newTestTx(to common.Address) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    7,
+		GasPrice: big.NewInt(1e10),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(0),
+		Data:     []byte{1, 2, 3},
+	})
+}
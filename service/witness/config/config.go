@@ -17,8 +17,16 @@ type Config struct {
 		//nolint:staticcheck
 		RedisDBOption tree.RedisDBOption `json:",optional"`
 		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+		//nolint:staticcheck
 		RoutinePoolSize    int `json:",optional"`
 		AssetTreeCacheSize int
+		// The number of blocks before the latest verified block whose tree versions are kept.
+		//nolint:staticcheck
+		RetainedBlocks int64 `json:",optional"`
 	}
-	LogConf logx.LogConf
+	// The number of blocks whose witness can be built ahead of the one being written to database.
+	//nolint:staticcheck
+	PipelineDepth int `json:",optional"`
+	LogConf       logx.LogConf
 }
@@ -1,7 +1,6 @@
 package witness
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -29,6 +28,8 @@ const (
 	UnprovedBlockWitnessTimeout = 10 * time.Minute
 
 	BlockProcessDelta = 10
+
+	defaultPipelineDepth = 4
 )
 
 type Witness struct {
@@ -53,6 +54,9 @@ type Witness struct {
 }
 
 func NewWitness(c config.Config) (*Witness, error) {
+	if err := tree.RegisterAssetTreeCacheMetrics(); err != nil {
+		return nil, err
+	}
 	datasource := c.Postgres.DataSource
 	db, err := gorm.Open(postgres.Open(datasource))
 	if err != nil {
@@ -69,6 +73,12 @@ func NewWitness(c config.Config) (*Witness, error) {
 		nftHistoryModel:     nft.NewL2NftHistoryModel(db),
 		proofModel:          proof.NewProofModel(db),
 	}
+	// add the binary witness column to tables created before the binary encoding,
+	// the json witnesses can be re-encoded later with the migrate-witness command
+	err = w.blockWitnessModel.CreateBlockWitnessTable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate block witness table, err: %v", err)
+	}
 	err = w.initState()
 	return w, err
 }
@@ -84,7 +94,7 @@ func (w *Witness) initState() error {
 	}
 
 	// dbinitializer tree database
-	treeCtx, err := tree.NewContext("witness", w.config.TreeDB.Driver, false, w.config.TreeDB.RoutinePoolSize, &w.config.TreeDB.LevelDBOption, &w.config.TreeDB.RedisDBOption)
+	treeCtx, err := tree.NewContext("witness", w.config.TreeDB.Driver, false, w.config.TreeDB.RoutinePoolSize, &w.config.TreeDB.LevelDBOption, &w.config.TreeDB.RedisDBOption, &w.config.TreeDB.BadgerDBOption)
 	if err != nil {
 		return err
 	}
@@ -139,31 +149,78 @@ func (w *Witness) GenerateBlockWitness() (err error) {
 		return err
 	}
 
+	// The trees are updated block by block in this goroutine, while the witness of the
+	// previous blocks is serialized and written to the database by the writer goroutine.
+	// The channel size limits how many blocks the trees can run ahead of the database.
+	witnessChan := make(chan *circuit.Block, w.pipelineDepth())
+	abortChan := make(chan struct{})
+	writerDone := make(chan struct{})
+	persistedHeight := latestWitnessHeight
+	var writeErr error
+	go func() {
+		defer close(writerDone)
+		for cryptoBlock := range witnessChan {
+			if writeErr != nil {
+				continue
+			}
+			err := w.saveBlockWitness(cryptoBlock)
+			if err != nil {
+				writeErr = fmt.Errorf("create unproved crypto block error, block:%d, err: %v", cryptoBlock.BlockNumber, err)
+				close(abortChan)
+				continue
+			}
+			persistedHeight = cryptoBlock.BlockNumber
+		}
+	}()
+
+	var buildErr error
 	// scan each block
+blockLoop:
 	for _, block := range blocks {
+		select {
+		case <-abortChan:
+			break blockLoop
+		default:
+		}
+
 		logx.Infof("construct witness for block %d", block.BlockHeight)
 		// Step1: construct witness
-		blockWitness, err := w.constructBlockWitness(block, latestVerifiedBlockNr)
+		cryptoBlock, err := w.constructBlockWitness(block, latestVerifiedBlockNr)
 		if err != nil {
-			return fmt.Errorf("failed to construct block witness, block:%d, err: %v", block.BlockHeight, err)
+			buildErr = fmt.Errorf("failed to construct block witness, block:%d, err: %v", block.BlockHeight, err)
+			break
 		}
 		// Step2: commit trees for witness
-		err = tree.CommitTrees(uint64(latestVerifiedBlockNr), w.accountTree, w.assetTrees, w.nftTree)
+		err = tree.CommitTrees(tree.PrunedVersion(latestVerifiedBlockNr, w.config.TreeDB.RetainedBlocks), w.accountTree, w.assetTrees, w.nftTree)
 		if err != nil {
-			return fmt.Errorf("unable to commit trees after txs is executed, block:%d, error: %v", block.BlockHeight, err)
+			buildErr = fmt.Errorf("unable to commit trees after txs is executed, block:%d, error: %v", block.BlockHeight, err)
+			break
 		}
-		// Step3: insert witness into database
-		err = w.blockWitnessModel.CreateBlockWitness(blockWitness)
-		if err != nil {
-			// rollback trees
-			rollBackErr := tree.RollBackTrees(uint64(block.BlockHeight)-1, w.accountTree, w.assetTrees, w.nftTree)
-			if rollBackErr != nil {
-				logx.Errorf("unable to rollback trees %v", rollBackErr)
-			}
-			return fmt.Errorf("create unproved crypto block error, block:%d, err: %v", block.BlockHeight, err)
+		// Step3: hand over the witness to the writer, which inserts it into database
+		witnessChan <- cryptoBlock
+	}
+	close(witnessChan)
+	<-writerDone
+
+	if writeErr != nil {
+		// rollback trees, including the blocks which were built ahead of the failed one
+		rollBackErr := tree.RollBackTrees(uint64(persistedHeight), w.accountTree, w.assetTrees, w.nftTree)
+		if rollBackErr == nil {
+			rollBackErr = tree.RestoreAssetTrees(w.accountModel, w.accountHistoryModel, persistedHeight, w.assetTrees)
 		}
+		if rollBackErr != nil {
+			logx.Errorf("unable to rollback trees %v", rollBackErr)
+		}
+		return writeErr
 	}
-	return nil
+	return buildErr
+}
+
+func (w *Witness) pipelineDepth() int {
+	if w.config.PipelineDepth <= 0 {
+		return defaultPipelineDepth
+	}
+	return w.config.PipelineDepth
 }
 
 func (w *Witness) RescheduleBlockWitness() {
@@ -231,7 +288,7 @@ func (w *Witness) getNextWitnessToCheck() (int64, error) {
 	return endToCheck + 1, nil
 }
 
-func (w *Witness) constructBlockWitness(block *block.Block, latestVerifiedBlockNr int64) (*blockwitness.BlockWitness, error) {
+func (w *Witness) constructBlockWitness(block *block.Block, latestVerifiedBlockNr int64) (*circuit.Block, error) {
 	var oldStateRoot, newStateRoot []byte
 	txsWitness := make([]*utils.TxWitness, 0, block.BlockSize)
 	// scan each transaction
@@ -279,16 +336,20 @@ func (w *Witness) constructBlockWitness(block *block.Block, latestVerifiedBlockN
 		Txs:             txsWitness,
 		Gas:             gasWitness,
 	}
-	bz, err := json.Marshal(b)
+	return b, nil
+}
+
+func (w *Witness) saveBlockWitness(cryptoBlock *circuit.Block) error {
+	bz, err := utils.EncodeBlockWitness(cryptoBlock)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	blockWitness := blockwitness.BlockWitness{
-		Height:      block.BlockHeight,
-		WitnessData: string(bz),
-		Status:      blockwitness.StatusPublished,
+		Height:        cryptoBlock.BlockNumber,
+		WitnessBinary: bz,
+		Status:        blockwitness.StatusPublished,
 	}
-	return &blockWitness, nil
+	return w.blockWitnessModel.CreateBlockWitness(&blockWitness)
 }
 
 func (w *Witness) Shutdown() {
@@ -0,0 +1,105 @@
+package witness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	utils "github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// emptyBlockModel serves empty blocks which don't change the genesis state.
+type emptyBlockModel struct {
+	block.BlockModel
+}
+
+func (m *emptyBlockModel) GetBlocksBetween(start int64, end int64) ([]*block.Block, error) {
+	blocks := make([]*block.Block, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		blocks = append(blocks, &block.Block{
+			BlockHeight: height,
+			BlockSize:   1,
+			StateRoot:   common.Bytes2Hex(tree.NilStateRoot),
+		})
+	}
+	return blocks, nil
+}
+
+func (m *emptyBlockModel) GetLatestVerifiedHeight() (int64, error) {
+	return 0, nil
+}
+
+// failingBlockWitnessModel fails to save any block witness.
+type failingBlockWitnessModel struct {
+	blockwitness.BlockWitnessModel
+}
+
+func (m *failingBlockWitnessModel) GetLatestBlockWitnessHeight() (int64, error) {
+	return 0, types.DbErrNotFound
+}
+
+func (m *failingBlockWitnessModel) CreateBlockWitness(witness *blockwitness.BlockWitness) error {
+	return types.DbErrSqlOperation
+}
+
+// emptyAccountHistoryModel serves a state without any accounts or nfts.
+type emptyAccountHistoryModel struct {
+	account.AccountHistoryModel
+	nft.L2NftHistoryModel
+}
+
+func (m *emptyAccountHistoryModel) GetValidAccountCount(blockHeight int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *emptyAccountHistoryModel) GetLatestAccountHistory(accountIndex, height int64) (*account.AccountHistory, error) {
+	return nil, types.DbErrNotFound
+}
+
+func (m *emptyAccountHistoryModel) GetAccountIndexesChangedAfter(height int64) ([]int64, error) {
+	return nil, nil
+}
+
+func TestGenerateBlockWitnessWithFailingWriter(t *testing.T) {
+	treeCtx, err := tree.NewContext("witness", tree.BadgerDB, false, 0, nil, nil, &tree.BadgerDBOption{File: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, tree.SetupTreeDB(treeCtx))
+	defer treeCtx.TreeDB.Close()
+
+	historyModel := &emptyAccountHistoryModel{}
+	w := &Witness{
+		treeCtx:             treeCtx,
+		blockModel:          &emptyBlockModel{},
+		accountHistoryModel: historyModel,
+		nftHistoryModel:     historyModel,
+		blockWitnessModel:   &failingBlockWitnessModel{},
+	}
+	w.accountTree, w.assetTrees, err = tree.InitAccountTree(w.accountModel, w.accountHistoryModel, 0, treeCtx, 16)
+	require.NoError(t, err)
+	w.nftTree, err = tree.InitNftTree(w.nftHistoryModel, 0, treeCtx)
+	require.NoError(t, err)
+	w.helper = utils.NewWitnessHelper(w.treeCtx, w.accountTree, w.nftTree, w.assetTrees, w.accountModel, w.accountHistoryModel)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.GenerateBlockWitness()
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("GenerateBlockWitness doesn't return after the writer fails")
+	}
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), types.DbErrSqlOperation.Error())
+	// the trees are rolled back to the persisted height
+	assert.Equal(t, common.Bytes2Hex(tree.NilStateRoot), common.Bytes2Hex(tree.ComputeStateRootHash(w.accountTree.Root(), w.nftTree.Root())))
+}
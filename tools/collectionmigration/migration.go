@@ -0,0 +1,127 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package collectionmigration
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+var collectionTxTypes = []int64{types.TxTypeCreateCollection, types.TxTypeMintNft, types.TxTypeDepositNft,
+	types.TxTypeWithdrawNft, types.TxTypeFullExitNft}
+
+// MigrateCollections fills the collection table from the CreateCollection txs of all the blocks,
+// and counts their nfts by the txs adding nfts to l2 or removing them, the collections created
+// by former versions are not in the table. The committer must be stopped during the migration,
+// the existing collections are replaced, so it can run again if it fails.
+func MigrateCollections(dsn string, batchSize int) error {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	collectionModel := collection.NewCollectionModel(db)
+
+	// create the collection table and the collection index of nfts in case no upgraded
+	// committer has run yet
+	err = collectionModel.CreateCollectionTable()
+	if err != nil {
+		return fmt.Errorf("failed to migrate collection table, err: %v", err)
+	}
+	err = nft.NewL2NftModel(db).CreateL2NftTable()
+	if err != nil {
+		return fmt.Errorf("failed to migrate l2 nft table, err: %v", err)
+	}
+
+	currentHeight, err := block.NewBlockModel(db).GetCurrentBlockHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get current block height, err: %v", err)
+	}
+	collections, err := ReplayCollections(tx.NewTxModel(db), currentHeight, batchSize)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(dbTx *gorm.DB) error {
+		err := collectionModel.DeleteAllCollectionsInTransact(dbTx)
+		if err != nil {
+			return err
+		}
+		for start := 0; start < len(collections); start += batchSize {
+			end := start + batchSize
+			if end > len(collections) {
+				end = len(collections)
+			}
+			err = collectionModel.CreateCollectionsInTransact(dbTx, collections[start:end])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save collections, err: %v", err)
+	}
+
+	logx.Infof("collection migration is done, %d collections are created up to block %d", len(collections), currentHeight)
+	return nil
+}
+
+// ReplayCollections returns the collections created in the blocks up to the height, with the
+// numbers of their nfts in l2 at the height.
+func ReplayCollections(txModel tx.TxModel, height int64, batchSize int) ([]*collection.Collection, error) {
+	var (
+		collections []*collection.Collection
+		nftCounts   = make(map[collection.Key]int64)
+		fromId      uint
+		replayed    int
+	)
+	for {
+		txs, err := txModel.GetTxsByTypesUpToHeight(collectionTxTypes, height, fromId, batchSize)
+		if err != nil {
+			if err == types.DbErrNotFound {
+				break
+			}
+			return nil, fmt.Errorf("failed to get collection txs, err: %v", err)
+		}
+		created, nftCountDeltas, err := collection.GetCollectionsOfTxs(-1, txs)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, created...)
+		for key, delta := range nftCountDeltas {
+			nftCounts[key] += delta
+		}
+		fromId = txs[len(txs)-1].ID + 1
+		replayed += len(txs)
+		logx.Infof("replayed %d collection txs, next tx id: %d", replayed, fromId)
+	}
+
+	for _, c := range collections {
+		c.NftCount = nftCounts[collection.Key{CreatorAccountIndex: c.CreatorAccountIndex, CollectionId: c.CollectionId}]
+	}
+	return collections, nil
+}
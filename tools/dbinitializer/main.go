@@ -31,6 +31,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/collection"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
 	"github.com/bnb-chain/zkbnb/dao/l1syncedblock"
@@ -39,6 +40,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/proof"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/dao/withdrawal"
 	"github.com/bnb-chain/zkbnb/tree"
 	"github.com/bnb-chain/zkbnb/types"
 )
@@ -75,6 +77,8 @@ type dao struct {
 	l1RollupTModel       l1rolluptx.L1RollupTxModel
 	nftModel             nft.L2NftModel
 	nftHistoryModel      nft.L2NftHistoryModel
+	collectionModel      collection.CollectionModel
+	withdrawalModel      withdrawal.WithdrawalModel
 }
 
 func Initialize(
@@ -92,7 +96,16 @@ func Initialize(
 	unmarshal, _ := json.Marshal(svrConf)
 	logx.Infof("init configs: %s", string(unmarshal))
 
-	dao := &dao{
+	dao := newDao(db)
+
+	dropTables(dao)
+	initTable(dao, &svrConf, bscTestNetworkRPC, localTestNetworkRPC)
+
+	return nil
+}
+
+func newDao(db *gorm.DB) *dao {
+	return &dao{
 		sysConfigModel:       sysconfig.NewSysConfigModel(db),
 		accountModel:         account.NewAccountModel(db),
 		accountHistoryModel:  account.NewAccountHistoryModel(db),
@@ -109,12 +122,15 @@ func Initialize(
 		l1RollupTModel:       l1rolluptx.NewL1RollupTxModel(db),
 		nftModel:             nft.NewL2NftModel(db),
 		nftHistoryModel:      nft.NewL2NftHistoryModel(db),
+		collectionModel:      collection.NewCollectionModel(db),
+		withdrawalModel:      withdrawal.NewWithdrawalModel(db),
 	}
+}
 
-	dropTables(dao)
-	initTable(dao, &svrConf, bscTestNetworkRPC, localTestNetworkRPC)
-
-	return nil
+// CreateTables creates the tables of all the services without the genesis state, it is
+// used to restore a node from a snapshot.
+func CreateTables(db *gorm.DB) {
+	createTables(newDao(db))
 }
 
 func initSysConfig(svrConf *contractAddr, bscTestNetworkRPC, localTestNetworkRPC string) []*sysconfig.SysConfig {
@@ -243,9 +259,11 @@ func dropTables(dao *dao) {
 	assert.Nil(nil, dao.l1RollupTModel.DropL1RollupTxTable())
 	assert.Nil(nil, dao.nftModel.DropL2NftTable())
 	assert.Nil(nil, dao.nftHistoryModel.DropL2NftHistoryTable())
+	assert.Nil(nil, dao.collectionModel.DropCollectionTable())
+	assert.Nil(nil, dao.withdrawalModel.DropWithdrawalTable())
 }
 
-func initTable(dao *dao, svrConf *contractAddr, bscTestNetworkRPC, localTestNetworkRPC string) {
+func createTables(dao *dao) {
 	assert.Nil(nil, dao.sysConfigModel.CreateSysConfigTable())
 	assert.Nil(nil, dao.accountModel.CreateAccountTable())
 	assert.Nil(nil, dao.accountHistoryModel.CreateAccountHistoryTable())
@@ -262,6 +280,12 @@ func initTable(dao *dao, svrConf *contractAddr, bscTestNetworkRPC, localTestNetw
 	assert.Nil(nil, dao.l1RollupTModel.CreateL1RollupTxTable())
 	assert.Nil(nil, dao.nftModel.CreateL2NftTable())
 	assert.Nil(nil, dao.nftHistoryModel.CreateL2NftHistoryTable())
+	assert.Nil(nil, dao.collectionModel.CreateCollectionTable())
+	assert.Nil(nil, dao.withdrawalModel.CreateWithdrawalTable())
+}
+
+func initTable(dao *dao, svrConf *contractAddr, bscTestNetworkRPC, localTestNetworkRPC string) {
+	createTables(dao)
 	rowsAffected, err := dao.assetModel.CreateAssets(initAssetsInfo(svrConf.BUSDToken))
 	if err != nil {
 		panic(err)
@@ -0,0 +1,295 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migratetreedb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+type Config struct {
+	TreeDB struct {
+		Driver tree.Driver
+		//nolint:staticcheck
+		LevelDBOption tree.LevelDBOption `json:",optional"`
+		//nolint:staticcheck
+		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+		//nolint:staticcheck
+		RoutinePoolSize int `json:",optional"`
+	}
+	LogConf logx.LogConf
+}
+
+// namespaces are migrated in this order, the asset trees of all accounts share the prefix.
+var namespaces = []string{tree.AccountPrefix, tree.AccountAssetPrefix, tree.NFTPrefix}
+
+// Checkpoint records the progress of a migration. The namespaces before Namespace are
+// migrated, and the keys of Namespace up to Position are written to the destination.
+type Checkpoint struct {
+	Service   string
+	Namespace string
+	Position  string
+	Keys      int64
+	Done      bool
+}
+
+// MigrateTreeDB copies the account, asset and nft trees of a service from the tree db of
+// the from config to the tree db of the to config with all their versions, and verifies the
+// roots of the trees at the end. The progress is saved into the checkpoint file after each
+// batch, a failed migration is resumed from it when run again. The service must be stopped
+// during the migration.
+func MigrateTreeDB(fromConfigFile, toConfigFile string, serviceName string, checkpointFile string, batchSize int) error {
+	var from, to Config
+	conf.MustLoad(fromConfigFile, &from)
+	conf.MustLoad(toConfigFile, &to)
+	logx.MustSetup(to.LogConf)
+	logx.DisableStat()
+
+	if from.TreeDB.Driver == tree.MemoryDB || to.TreeDB.Driver == tree.MemoryDB {
+		return fmt.Errorf("trees of the memorydb driver are not persisted")
+	}
+	fromCtx, err := newTreeContext(serviceName, &from)
+	if err != nil {
+		return err
+	}
+	toCtx, err := newTreeContext(serviceName, &to)
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := loadCheckpoint(checkpointFile, serviceName)
+	if err != nil {
+		return err
+	}
+	source, err := newKeySource(fromCtx)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	fromCtx.TreeDB = source.TreeDB()
+	err = tree.SetupTreeDB(toCtx)
+	if err != nil {
+		return fmt.Errorf("failed to setup tree db, err: %v", err)
+	}
+	defer toCtx.TreeDB.Close()
+
+	if checkpoint.Namespace == "" && !checkpoint.Done {
+		// Not started yet, make sure the trees of the destination are not overwritten.
+		accountTree, err := openTree(toCtx, tree.AccountPrefix)
+		if err != nil {
+			return err
+		}
+		if accountTree.LatestVersion() != 0 {
+			return fmt.Errorf("account tree of %s already exists in the destination, version: %d",
+				serviceName, accountTree.LatestVersion())
+		}
+		checkpoint.Namespace = namespaces[0]
+	}
+
+	for !checkpoint.Done {
+		err = migrateNamespace(source, toCtx.TreeDB, checkpoint, checkpointFile, batchSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = verifyTrees(fromCtx, toCtx)
+	if err != nil {
+		return err
+	}
+	logx.Infof("trees of %s are migrated from %s to %s, keys: %d", serviceName,
+		from.TreeDB.Driver, to.TreeDB.Driver, checkpoint.Keys)
+	return os.Remove(checkpointFile)
+}
+
+func newTreeContext(serviceName string, c *Config) (*tree.Context, error) {
+	treeCtx, err := tree.NewContext(serviceName, c.TreeDB.Driver, false, c.TreeDB.RoutinePoolSize,
+		&c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption, &c.TreeDB.BadgerDBOption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tree context, err: %v", err)
+	}
+	return treeCtx, nil
+}
+
+// migrateNamespace copies the keys of the namespace of the checkpoint from the position of
+// the checkpoint, and moves the checkpoint to the next namespace.
+func migrateNamespace(source keySource, dst database.TreeDB, checkpoint *Checkpoint, checkpointFile string, batchSize int) error {
+	namespace := checkpoint.Namespace
+	prefix := []byte(strings.Join([]string{checkpoint.Service, namespace}, ":"))
+	batch := dst.NewBatch()
+	pending := 0
+	position := checkpoint.Position
+	flush := func() error {
+		err := batch.Write()
+		if err != nil {
+			return fmt.Errorf("failed to write keys, err: %v", err)
+		}
+		batch.Reset()
+		checkpoint.Position = position
+		checkpoint.Keys += int64(pending)
+		pending = 0
+		return saveCheckpoint(checkpointFile, checkpoint)
+	}
+
+	err := source.Iterate(prefix, checkpoint.Position, func(key, value []byte, keyPosition string) error {
+		err := batch.Set(key, value)
+		if err != nil {
+			return err
+		}
+		pending++
+		position = keyPosition
+		if pending < batchSize {
+			return nil
+		}
+		err = flush()
+		if err != nil {
+			return err
+		}
+		logx.Infof("migrated %d keys, namespace: %s", checkpoint.Keys, namespace)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate namespace %s, err: %v", namespace, err)
+	}
+
+	for i := range namespaces {
+		if namespaces[i] != namespace {
+			continue
+		}
+		if i+1 < len(namespaces) {
+			checkpoint.Namespace = namespaces[i+1]
+		} else {
+			checkpoint.Done = true
+		}
+	}
+	position = ""
+	err = flush()
+	if err != nil {
+		return err
+	}
+	logx.Infof("namespace %s is migrated, keys: %d", namespace, checkpoint.Keys)
+	return nil
+}
+
+func loadCheckpoint(checkpointFile string, serviceName string) (*Checkpoint, error) {
+	bz, err := ioutil.ReadFile(checkpointFile)
+	if os.IsNotExist(err) {
+		return &Checkpoint{Service: serviceName}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint, err: %v", err)
+	}
+	checkpoint := &Checkpoint{}
+	err = json.Unmarshal(bz, checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint, err: %v", err)
+	}
+	if checkpoint.Service != serviceName {
+		return nil, fmt.Errorf("checkpoint is for service %s", checkpoint.Service)
+	}
+	logx.Infof("resume the migration from namespace %s, keys: %d", checkpoint.Namespace, checkpoint.Keys)
+	return checkpoint, nil
+}
+
+func saveCheckpoint(checkpointFile string, checkpoint *Checkpoint) error {
+	bz, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that the checkpoint is never partially written.
+	err = ioutil.WriteFile(checkpointFile+".tmp", bz, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint, err: %v", err)
+	}
+	return os.Rename(checkpointFile+".tmp", checkpointFile)
+}
+
+func openTree(treeCtx *tree.Context, namespace string) (bsmt.SparseMerkleTree, error) {
+	treeHeight, nilHash := uint8(tree.AccountTreeHeight), tree.NilAccountNodeHash
+	switch {
+	case namespace == tree.NFTPrefix:
+		treeHeight, nilHash = tree.NftTreeHeight, tree.NilNftNodeHash
+	case strings.HasPrefix(namespace, tree.AccountAssetPrefix):
+		treeHeight, nilHash = tree.AssetTreeHeight, tree.NilAccountAssetNodeHash
+	}
+	smt, err := bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(), tree.SetNamespace(treeCtx, namespace),
+		treeHeight, nilHash, treeCtx.Options(0)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tree %s, err: %v", namespace, err)
+	}
+	return smt, nil
+}
+
+// verifyTrees compares the roots and the versions of the trees in both tree dbs. The asset
+// trees are compared for all the accounts in the account tree, account indexes are dense.
+func verifyTrees(fromCtx, toCtx *tree.Context) error {
+	compare := func(namespace string) (bsmt.SparseMerkleTree, error) {
+		fromTree, err := openTree(fromCtx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		toTree, err := openTree(toCtx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(fromTree.Root(), toTree.Root()) ||
+			fromTree.LatestVersion() != toTree.LatestVersion() ||
+			fromTree.RecentVersion() != toTree.RecentVersion() {
+			return nil, fmt.Errorf("tree %s mismatches, source root: %s, versions: %d-%d, destination root: %s, versions: %d-%d",
+				namespace, common.Bytes2Hex(fromTree.Root()), fromTree.RecentVersion(), fromTree.LatestVersion(),
+				common.Bytes2Hex(toTree.Root()), toTree.RecentVersion(), toTree.LatestVersion())
+		}
+		return fromTree, nil
+	}
+
+	if _, err := compare(tree.NFTPrefix); err != nil {
+		return err
+	}
+	accountTree, err := compare(tree.AccountPrefix)
+	if err != nil {
+		return err
+	}
+	index := int64(0)
+	for ; ; index++ {
+		_, err := accountTree.Get(uint64(index), nil)
+		if err == bsmt.ErrEmptyRoot || err == bsmt.ErrNodeNotFound {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get account %d from tree, err: %v", index, err)
+		}
+		if _, err := compare(tree.AccountAssetNamespace(index)); err != nil {
+			return err
+		}
+	}
+	logx.Infof("roots of the account tree, the asset trees of %d accounts and the nft tree are verified", index)
+	return nil
+}
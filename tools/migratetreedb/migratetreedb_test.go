@@ -0,0 +1,137 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migratetreedb
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/conf"
+
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+func writeConfig(t *testing.T, driver tree.Driver, file string) string {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(fmt.Sprintf(
+		"TreeDB:\n  Driver: %s\n  LevelDBOption:\n    File: %s\n  BadgerDBOption:\n    File: %s\nLogConf:\n  Level: error\n",
+		driver, file, file)), 0644))
+	return configFile
+}
+
+// writeTrees commits 3 blocks of 3 accounts with their asset trees and an nft into the tree db.
+func writeTrees(t *testing.T, configFile string) {
+	var c Config
+	require.NoError(t, conf.Load(configFile, &c))
+	treeCtx, err := newTreeContext("committer", &c)
+	require.NoError(t, err)
+	require.NoError(t, tree.SetupTreeDB(treeCtx))
+	defer treeCtx.TreeDB.Close()
+
+	accountTree, err := openTree(treeCtx, tree.AccountPrefix)
+	require.NoError(t, err)
+	nftTree, err := openTree(treeCtx, tree.NFTPrefix)
+	require.NoError(t, err)
+	for height := 1; height <= 3; height++ {
+		for index := int64(0); index < 3; index++ {
+			assetTree, err := openTree(treeCtx, tree.AccountAssetNamespace(index))
+			require.NoError(t, err)
+			require.NoError(t, assetTree.Set(uint64(height), tree.NilAccountNodeHash))
+			_, err = assetTree.Commit(nil)
+			require.NoError(t, err)
+			require.NoError(t, accountTree.Set(uint64(index), assetTree.Root()))
+		}
+		require.NoError(t, nftTree.Set(uint64(height), tree.NilAccountAssetNodeHash))
+		_, err = accountTree.Commit(nil)
+		require.NoError(t, err)
+		_, err = nftTree.Commit(nil)
+		require.NoError(t, err)
+	}
+}
+
+func TestMigrateTreeDB(t *testing.T) {
+	from := writeConfig(t, tree.LevelDB, filepath.Join(t.TempDir(), "leveldb"))
+	writeTrees(t, from)
+	to := writeConfig(t, tree.BadgerDB, filepath.Join(t.TempDir(), "badgerdb"))
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	require.NoError(t, MigrateTreeDB(from, to, "committer", checkpointFile, 10))
+	assert.NoFileExists(t, checkpointFile)
+
+	// the trees of the service exist in the destination now
+	assert.ErrorContains(t, MigrateTreeDB(from, to, "committer", checkpointFile, 10), "already exists")
+
+	// and can be migrated back
+	back := writeConfig(t, tree.LevelDB, filepath.Join(t.TempDir(), "leveldb"))
+	require.NoError(t, MigrateTreeDB(to, back, "committer", checkpointFile, 10))
+}
+
+// failingSource fails after a number of keys, like a migration which is interrupted.
+type failingSource struct {
+	keySource
+	keys int
+}
+
+func (s *failingSource) Iterate(prefix []byte, position string, fn func(key, value []byte, position string) error) error {
+	return s.keySource.Iterate(prefix, position, func(key, value []byte, position string) error {
+		if s.keys == 0 {
+			return errors.New("interrupted")
+		}
+		s.keys--
+		return fn(key, value, position)
+	})
+}
+
+func TestResumeMigration(t *testing.T) {
+	from := writeConfig(t, tree.LevelDB, filepath.Join(t.TempDir(), "leveldb"))
+	writeTrees(t, from)
+	to := writeConfig(t, tree.BadgerDB, filepath.Join(t.TempDir(), "badgerdb"))
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	var c Config
+	require.NoError(t, conf.Load(to, &c))
+	toCtx, err := newTreeContext("committer", &c)
+	require.NoError(t, err)
+	require.NoError(t, tree.SetupTreeDB(toCtx))
+	require.NoError(t, conf.Load(from, &c))
+	fromCtx, err := newTreeContext("committer", &c)
+	require.NoError(t, err)
+	source, err := newKeySource(fromCtx)
+	require.NoError(t, err)
+
+	checkpoint := &Checkpoint{Service: "committer", Namespace: tree.AccountPrefix}
+	require.NoError(t, migrateNamespace(source, toCtx.TreeDB, checkpoint, checkpointFile, 10))
+	accountKeys := checkpoint.Keys
+	err = migrateNamespace(&failingSource{keySource: source, keys: 3}, toCtx.TreeDB, checkpoint, checkpointFile, 1)
+	assert.ErrorContains(t, err, "interrupted")
+	require.NoError(t, source.Close())
+	require.NoError(t, toCtx.TreeDB.Close())
+
+	saved, err := loadCheckpoint(checkpointFile, "committer")
+	require.NoError(t, err)
+	assert.Equal(t, accountKeys+3, saved.Keys)
+	assert.Equal(t, tree.AccountAssetPrefix, saved.Namespace)
+	assert.NotEmpty(t, saved.Position)
+
+	require.NoError(t, MigrateTreeDB(from, to, "committer", checkpointFile, 10))
+	assert.NoFileExists(t, checkpointFile)
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migratetreedb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis/v8"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/bnb-chain/zkbnb-smt/database"
+	smtleveldb "github.com/bnb-chain/zkbnb-smt/database/leveldb"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+// keySource streams the keys of a tree db. Iterate calls fn with each key with the prefix
+// after the position, together with the position to resume from so that no key after the
+// ones already handled is missed.
+type keySource interface {
+	Iterate(prefix []byte, position string, fn func(key, value []byte, position string) error) error
+	// TreeDB returns the tree db to open the trees of the source.
+	TreeDB() database.TreeDB
+	Close() error
+}
+
+func newKeySource(treeCtx *tree.Context) (keySource, error) {
+	switch treeCtx.Driver {
+	case tree.LevelDB:
+		db, err := leveldb.OpenFile(treeCtx.LevelDBOption.File, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open leveldb, err: %v", err)
+		}
+		return &levelDBSource{db: db}, nil
+	case tree.BadgerDB:
+		db, err := tree.NewBadgerDB(treeCtx.BadgerDBOption, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open badgerdb, err: %v", err)
+		}
+		return &badgerDBSource{db: db}, nil
+	case tree.RedisDB:
+		err := tree.SetupTreeDB(treeCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup tree db, err: %v", err)
+		}
+		return newRedisSource(treeCtx.TreeDB, treeCtx.RedisDBOption)
+	}
+	return nil, fmt.Errorf("trees of the %s driver can not be migrated", treeCtx.Driver)
+}
+
+// levelDBSource iterates the keys in order, the position is the last key handled.
+type levelDBSource struct {
+	db *leveldb.DB
+}
+
+func (s *levelDBSource) Iterate(prefix []byte, position string, fn func(key, value []byte, position string) error) error {
+	keyRange := util.BytesPrefix(prefix)
+	if position != "" {
+		keyRange.Start = append(common.FromHex(position), 0)
+	}
+	it := s.db.NewIterator(keyRange, nil)
+	defer it.Release()
+	for it.Next() {
+		err := fn(it.Key(), it.Value(), common.Bytes2Hex(it.Key()))
+		if err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+func (s *levelDBSource) TreeDB() database.TreeDB {
+	return smtleveldb.NewFromExistLevelDB(s.db)
+}
+
+func (s *levelDBSource) Close() error {
+	return s.db.Close()
+}
+
+// badgerDBSource iterates the keys in order, the position is the last key handled.
+type badgerDBSource struct {
+	db *tree.BadgerDatabase
+}
+
+func (s *badgerDBSource) Iterate(prefix []byte, position string, fn func(key, value []byte, position string) error) error {
+	start := prefix
+	if position != "" {
+		start = append(common.FromHex(position), 0)
+	}
+	return s.db.Iterate(prefix, start, func(key, value []byte) error {
+		return fn(key, value, common.Bytes2Hex(key))
+	})
+}
+
+func (s *badgerDBSource) TreeDB() database.TreeDB {
+	return s.db
+}
+
+func (s *badgerDBSource) Close() error {
+	return s.db.Close()
+}
+
+// redisSource scans the keys of each master node in turn. The keys are not ordered, the
+// position is the node and the cursor of the page of keys being handled, which is scanned
+// again when resumed.
+type redisSource struct {
+	treeDB  database.TreeDB
+	clients []*redis.Client
+	closer  func() error
+}
+
+func newRedisSource(treeDB database.TreeDB, option *tree.RedisDBOption) (*redisSource, error) {
+	source := &redisSource{treeDB: treeDB}
+	if len(option.ClusterAddr) == 0 {
+		client := redis.NewClient(&redis.Options{
+			Addr:     option.Addr,
+			Username: option.Username,
+			Password: option.Password,
+		})
+		source.clients = []*redis.Client{client}
+		source.closer = client.Close
+		return source, nil
+	}
+
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs:    option.ClusterAddr,
+		Username: option.Username,
+		Password: option.Password,
+	})
+	var lock sync.Mutex
+	err := cluster.ForEachMaster(context.Background(), func(ctx context.Context, client *redis.Client) error {
+		lock.Lock()
+		defer lock.Unlock()
+		source.clients = append(source.clients, client)
+		return nil
+	})
+	if err != nil {
+		cluster.Close()
+		return nil, fmt.Errorf("failed to get master nodes, err: %v", err)
+	}
+	sort.Slice(source.clients, func(i, j int) bool {
+		return source.clients[i].Options().Addr < source.clients[j].Options().Addr
+	})
+	source.closer = cluster.Close
+	return source, nil
+}
+
+func (s *redisSource) Iterate(prefix []byte, position string, fn func(key, value []byte, position string) error) error {
+	ctx := context.Background()
+	node, cursor, err := parseRedisPosition(position)
+	if err != nil {
+		return err
+	}
+	for ; node < len(s.clients); node, cursor = node+1, 0 {
+		client := s.clients[node]
+		for {
+			keys, next, err := client.Scan(ctx, cursor, string(prefix)+"*", 1000).Result()
+			if err != nil {
+				return fmt.Errorf("failed to scan keys, err: %v", err)
+			}
+			pipe := client.Pipeline()
+			values := make([]*redis.StringCmd, len(keys))
+			for i, key := range keys {
+				values[i] = pipe.Get(ctx, key)
+			}
+			if len(keys) != 0 {
+				_, err = pipe.Exec(ctx)
+				if err != nil && err != redis.Nil {
+					return fmt.Errorf("failed to get values, err: %v", err)
+				}
+			}
+			for i, key := range keys {
+				value, err := values[i].Bytes()
+				if err == redis.Nil {
+					// deleted after it is scanned
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to get value of %s, err: %v", key, err)
+				}
+				err = fn([]byte(key), value, fmt.Sprintf("%d:%d", node, cursor))
+				if err != nil {
+					return err
+				}
+			}
+			if next == 0 {
+				break
+			}
+			cursor = next
+		}
+	}
+	return nil
+}
+
+func parseRedisPosition(position string) (node int, cursor uint64, err error) {
+	if position == "" {
+		return 0, 0, nil
+	}
+	parts := strings.Split(position, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid position %s", position)
+	}
+	node, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position %s", position)
+	}
+	cursor, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position %s", position)
+	}
+	return node, cursor, nil
+}
+
+func (s *redisSource) TreeDB() database.TreeDB {
+	return s.treeDB
+}
+
+func (s *redisSource) Close() error {
+	s.treeDB.Close()
+	return s.closer()
+}
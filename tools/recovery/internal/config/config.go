@@ -19,6 +19,8 @@ type Config struct {
 		//nolint:staticcheck
 		RedisDBOption tree.RedisDBOption `json:",optional"`
 		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+		//nolint:staticcheck
 		RoutinePoolSize    int `json:",optional"`
 		AssetTreeCacheSize int
 	}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/nft"
 	"github.com/bnb-chain/zkbnb/tools/recovery/internal/config"
 )
@@ -16,6 +17,7 @@ type ServiceContext struct {
 	AccountModel        account.AccountModel
 	AccountHistoryModel account.AccountHistoryModel
 	NftHistoryModel     nft.L2NftHistoryModel
+	BlockModel          block.BlockModel
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -28,5 +30,6 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		AccountModel:        account.NewAccountModel(db),
 		AccountHistoryModel: account.NewAccountHistoryModel(db),
 		NftHistoryModel:     nft.NewL2NftHistoryModel(db),
+		BlockModel:          block.NewBlockModel(db),
 	}
 }
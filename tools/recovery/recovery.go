@@ -27,13 +27,12 @@ func RecoveryTreeDB(
 	})
 
 	// dbinitializer tree database
-	treeCtx, err := tree.NewContext(serviceName, c.TreeDB.Driver, true, c.TreeDB.RoutinePoolSize, &c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption)
+	treeCtx, err := tree.NewContext(serviceName, c.TreeDB.Driver, true, c.TreeDB.RoutinePoolSize, &c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption, &c.TreeDB.BadgerDBOption)
 	if err != nil {
 		logx.Errorf("Init tree database failed: %s", err)
 		return
 	}
 
-	treeCtx.SetOptions(bsmt.InitializeVersion(bsmt.Version(blockHeight) - 1))
 	treeCtx.SetBatchReloadSize(batchSize)
 	err = tree.SetupTreeDB(treeCtx)
 	if err != nil {
@@ -41,6 +40,27 @@ func RecoveryTreeDB(
 		return
 	}
 
+	// replay the histories above the newest consistent tree version if the trees exist
+	replayed, err := tree.ReplayTreesFromLatestVersion(
+		ctx.AccountModel,
+		ctx.AccountHistoryModel,
+		ctx.NftHistoryModel,
+		ctx.BlockModel,
+		blockHeight,
+		treeCtx,
+		c.TreeDB.AssetTreeCacheSize,
+	)
+	if err != nil {
+		logx.Errorf("ReplayTreesFromLatestVersion error: %s", err.Error())
+		return
+	}
+	if replayed {
+		logx.Infof("trees are recovered to block %d", blockHeight)
+		return
+	}
+
+	treeCtx.SetOptions(bsmt.InitializeVersion(bsmt.Version(blockHeight) - 1))
+
 	// dbinitializer accountTree and accountStateTrees
 	_, _, err = tree.InitAccountTree(
 		ctx.AccountModel,
@@ -0,0 +1,176 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/asset"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/sysconfig"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tools/collectionmigration"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// ExportSnapshot writes the state at a verified block height into dir. The latest verified
+// height is used if blockHeight is 0.
+func ExportSnapshot(dsn string, dir string, blockHeight int64, batchSize int) error {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	blockModel := block.NewBlockModel(db)
+
+	verifiedHeight, err := blockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get latest verified height, err: %v", err)
+	}
+	if blockHeight == 0 {
+		blockHeight = verifiedHeight
+	}
+	if blockHeight > verifiedHeight {
+		return fmt.Errorf("block %d is not verified, latest verified height: %d", blockHeight, verifiedHeight)
+	}
+
+	state := &State{}
+	state.Block, err = blockModel.GetBlockByHeightWithoutTx(blockHeight)
+	if err != nil {
+		return fmt.Errorf("failed to get block %d, err: %v", blockHeight, err)
+	}
+	// The created time is kept, it is the timestamp of the stored block info on l1.
+	state.Block.ID = 0
+
+	state.Accounts, err = exportAccounts(account.NewAccountModel(db), account.NewAccountHistoryModel(db), blockHeight, batchSize)
+	if err != nil {
+		return err
+	}
+	state.Nfts, err = exportNfts(nft.NewL2NftHistoryModel(db), blockHeight, batchSize)
+	if err != nil {
+		return err
+	}
+	state.Assets, err = exportAssets(asset.NewAssetModel(db), batchSize)
+	if err != nil {
+		return err
+	}
+	// the collection table holds the latest nft counts, so the collections are replayed up to the height
+	state.Collections, err = collectionmigration.ReplayCollections(tx.NewTxModel(db), blockHeight, batchSize)
+	if err != nil {
+		return err
+	}
+	state.SysConfigs, err = sysconfig.NewSysConfigModel(db).GetSysConfigs()
+	if err != nil {
+		return fmt.Errorf("failed to get sys configs, err: %v", err)
+	}
+	for _, sysConfig := range state.SysConfigs {
+		sysConfig.Model = gorm.Model{}
+	}
+
+	manifest, err := WriteSnapshot(dir, state)
+	if err != nil {
+		return err
+	}
+	logx.Infof("snapshot of block %d is exported to %s, state root: %s, accounts: %d, nfts: %d, collections: %d",
+		manifest.BlockHeight, dir, manifest.StateRoot, manifest.Accounts, manifest.Nfts, manifest.Collections)
+	return nil
+}
+
+// exportAccounts returns the accounts at the block height, the same way the account tree is
+// reloaded from the account history.
+func exportAccounts(accountModel account.AccountModel, accountHistoryModel account.AccountHistoryModel,
+	blockHeight int64, batchSize int) ([]*account.Account, error) {
+	var accounts []*account.Account
+	for {
+		_, accountHistories, err := accountHistoryModel.GetValidAccounts(blockHeight, batchSize, len(accounts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account histories, err: %v", err)
+		}
+		for _, accountHistory := range accountHistories {
+			accountInfo, err := accountModel.GetAccountByIndex(accountHistory.AccountIndex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get account %d, err: %v", accountHistory.AccountIndex, err)
+			}
+			accountInfo.Model = gorm.Model{}
+			accountInfo.Nonce = types.EmptyNonce
+			if accountHistory.Nonce != types.EmptyNonce {
+				accountInfo.Nonce = accountHistory.Nonce
+			}
+			accountInfo.CollectionNonce = types.EmptyCollectionNonce
+			if accountHistory.CollectionNonce != types.EmptyCollectionNonce {
+				accountInfo.CollectionNonce = accountHistory.CollectionNonce
+			}
+			accountInfo.AssetInfo = accountHistory.AssetInfo
+			accountInfo.AssetRoot = accountHistory.AssetRoot
+			accountInfo.Status = account.AccountStatusConfirmed
+			accounts = append(accounts, accountInfo)
+		}
+		if len(accountHistories) < batchSize {
+			return accounts, nil
+		}
+	}
+}
+
+func exportNfts(nftHistoryModel nft.L2NftHistoryModel, blockHeight int64, batchSize int) ([]*nft.L2Nft, error) {
+	var nfts []*nft.L2Nft
+	for {
+		_, nftHistories, err := nftHistoryModel.GetLatestNftsByBlockHeight(blockHeight, batchSize, len(nfts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nft histories, err: %v", err)
+		}
+		for _, nftHistory := range nftHistories {
+			nfts = append(nfts, &nft.L2Nft{
+				NftIndex:            nftHistory.NftIndex,
+				CreatorAccountIndex: nftHistory.CreatorAccountIndex,
+				OwnerAccountIndex:   nftHistory.OwnerAccountIndex,
+				NftContentHash:      nftHistory.NftContentHash,
+				NftL1Address:        nftHistory.NftL1Address,
+				NftL1TokenId:        nftHistory.NftL1TokenId,
+				CreatorTreasuryRate: nftHistory.CreatorTreasuryRate,
+				CollectionId:        nftHistory.CollectionId,
+			})
+		}
+		if len(nftHistories) < batchSize {
+			return nfts, nil
+		}
+	}
+}
+
+func exportAssets(assetModel asset.AssetModel, batchSize int) ([]*asset.Asset, error) {
+	var assets []*asset.Asset
+	for {
+		batch, err := assetModel.GetAssets(int64(batchSize), int64(len(assets)))
+		if err != nil {
+			if err == types.DbErrNotFound {
+				return assets, nil
+			}
+			return nil, fmt.Errorf("failed to get assets, err: %v", err)
+		}
+		for _, l2Asset := range batch {
+			l2Asset.Model = gorm.Model{}
+			assets = append(assets, l2Asset)
+		}
+		if len(batch) < batchSize {
+			return assets, nil
+		}
+	}
+}
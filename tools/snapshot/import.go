@@ -0,0 +1,161 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/asset"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/sysconfig"
+	"github.com/bnb-chain/zkbnb/tools/dbinitializer"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// ImportSnapshot verifies the snapshot in dir and restores it into an empty database. The
+// account and nft states are also written as the history at the block height, so that the
+// trees of the services are rebuilt from them.
+func ImportSnapshot(dsn string, dir string, batchSize int) error {
+	manifest, state, err := ReadSnapshot(dir)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot, err: %v", err)
+	}
+	logx.Infof("snapshot of block %d is verified, state root: %s", manifest.BlockHeight, manifest.StateRoot)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	var (
+		blockModel          = block.NewBlockModel(db)
+		accountModel        = account.NewAccountModel(db)
+		accountHistoryModel = account.NewAccountHistoryModel(db)
+		nftModel            = nft.NewL2NftModel(db)
+		nftHistoryModel     = nft.NewL2NftHistoryModel(db)
+		assetModel          = asset.NewAssetModel(db)
+		sysConfigModel      = sysconfig.NewSysConfigModel(db)
+		collectionModel     = collection.NewCollectionModel(db)
+	)
+
+	dbinitializer.CreateTables(db)
+	currentHeight, err := blockModel.GetCurrentBlockHeight()
+	if err == nil {
+		return fmt.Errorf("database is not empty, current block height: %d", currentHeight)
+	}
+	if err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get current block height, err: %v", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := blockModel.CreateBlockInTransact(tx, state.Block)
+		if err != nil {
+			return err
+		}
+		for start := 0; start < len(state.Assets); start += batchSize {
+			err = assetModel.CreateAssetsInTransact(tx, state.Assets[start:batchEnd(start, batchSize, len(state.Assets))])
+			if err != nil {
+				return err
+			}
+		}
+		if len(state.SysConfigs) != 0 {
+			err = sysConfigModel.CreateSysConfigsInTransact(tx, state.SysConfigs)
+			if err != nil {
+				return err
+			}
+		}
+
+		for start := 0; start < len(state.Accounts); start += batchSize {
+			accounts := state.Accounts[start:batchEnd(start, batchSize, len(state.Accounts))]
+			histories := make([]*account.AccountHistory, 0, len(accounts))
+			for _, accountInfo := range accounts {
+				histories = append(histories, &account.AccountHistory{
+					AccountIndex:    accountInfo.AccountIndex,
+					Nonce:           accountInfo.Nonce,
+					CollectionNonce: accountInfo.CollectionNonce,
+					AssetInfo:       accountInfo.AssetInfo,
+					AssetRoot:       accountInfo.AssetRoot,
+					L2BlockHeight:   manifest.BlockHeight,
+				})
+			}
+			err = accountModel.CreateAccountsInTransact(tx, accounts)
+			if err != nil {
+				return err
+			}
+			err = accountHistoryModel.CreateAccountHistoriesInTransact(tx, histories)
+			if err != nil {
+				return err
+			}
+		}
+
+		for start := 0; start < len(state.Nfts); start += batchSize {
+			nfts := state.Nfts[start:batchEnd(start, batchSize, len(state.Nfts))]
+			histories := make([]*nft.L2NftHistory, 0, len(nfts))
+			for _, nftInfo := range nfts {
+				histories = append(histories, &nft.L2NftHistory{
+					NftIndex:            nftInfo.NftIndex,
+					CreatorAccountIndex: nftInfo.CreatorAccountIndex,
+					OwnerAccountIndex:   nftInfo.OwnerAccountIndex,
+					NftContentHash:      nftInfo.NftContentHash,
+					NftL1Address:        nftInfo.NftL1Address,
+					NftL1TokenId:        nftInfo.NftL1TokenId,
+					CreatorTreasuryRate: nftInfo.CreatorTreasuryRate,
+					CollectionId:        nftInfo.CollectionId,
+					L2BlockHeight:       manifest.BlockHeight,
+				})
+			}
+			err = nftModel.CreateNftsInTransact(tx, nfts)
+			if err != nil {
+				return err
+			}
+			err = nftHistoryModel.CreateNftHistoriesInTransact(tx, histories)
+			if err != nil {
+				return err
+			}
+		}
+
+		for start := 0; start < len(state.Collections); start += batchSize {
+			err = collectionModel.CreateCollectionsInTransact(tx,
+				state.Collections[start:batchEnd(start, batchSize, len(state.Collections))])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import snapshot, err: %v", err)
+	}
+
+	logx.Infof("snapshot of block %d is imported, accounts: %d, nfts: %d, collections: %d, run `zkbnb tree recovery` "+
+		"at height %d for the services using a persistent tree database", manifest.BlockHeight, manifest.Accounts,
+		manifest.Nfts, manifest.Collections, manifest.BlockHeight)
+	return nil
+}
+
+func batchEnd(start, batchSize, total int) int {
+	if start+batchSize > total {
+		return total
+	}
+	return start + batchSize
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/common"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database/memory"
+	"github.com/bnb-chain/zkbnb/common/chain"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/asset"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/sysconfig"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+// Version is the version of the snapshot format, the collection file is added in version 2.
+const Version = 2
+
+const (
+	ManifestFile   = "manifest.json"
+	BlockFile      = "block.json"
+	AccountFile    = "account.json"
+	NftFile        = "l2_nft.json"
+	AssetFile      = "asset.json"
+	SysConfigFile  = "sys_config.json"
+	CollectionFile = "collection.json"
+)
+
+// Manifest describes a snapshot, which is a directory holding the manifest and one json file
+// for each table. The checksums are the hex encoded sha256 of the files.
+type Manifest struct {
+	Version     int
+	BlockHeight int64
+	StateRoot   string
+	AccountRoot string
+	NftRoot     string
+	Accounts    int
+	Nfts        int
+	Collections int
+	Checksums   map[string]string
+}
+
+// State is the l2 state at the height of a snapshot. Accounts and nfts hold the state at the
+// height, which is behind the account and l2_nft tables if newer blocks are executed.
+type State struct {
+	Block      *block.Block
+	Accounts   []*account.Account
+	Nfts       []*nft.L2Nft
+	Assets     []*asset.Asset
+	SysConfigs []*sysconfig.SysConfig
+	// The collections created up to the height, with the numbers of their nfts in l2 at the height.
+	Collections []*collection.Collection
+}
+
+// files returns the files of the tables in a snapshot of the version.
+func (s *State) files(version int) map[string]interface{} {
+	files := map[string]interface{}{
+		BlockFile:     &s.Block,
+		AccountFile:   &s.Accounts,
+		NftFile:       &s.Nfts,
+		AssetFile:     &s.Assets,
+		SysConfigFile: &s.SysConfigs,
+	}
+	if version >= 2 {
+		files[CollectionFile] = &s.Collections
+	}
+	return files
+}
+
+// WriteSnapshot writes the state into dir, which is created if not exists.
+func WriteSnapshot(dir string, state *State) (*Manifest, error) {
+	accountRoot, nftRoot, err := ComputeRoots(state.Accounts, state.Nfts)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		Version:     Version,
+		BlockHeight: state.Block.BlockHeight,
+		StateRoot:   common.Bytes2Hex(tree.ComputeStateRootHash(accountRoot, nftRoot)),
+		AccountRoot: common.Bytes2Hex(accountRoot),
+		NftRoot:     common.Bytes2Hex(nftRoot),
+		Accounts:    len(state.Accounts),
+		Nfts:        len(state.Nfts),
+		Collections: len(state.Collections),
+		Checksums:   make(map[string]string),
+	}
+	if manifest.StateRoot != state.Block.StateRoot {
+		return nil, fmt.Errorf("state root %s does not match state root %s of block %d",
+			manifest.StateRoot, state.Block.StateRoot, state.Block.BlockHeight)
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	for name, records := range state.files(Version) {
+		bz, err := json.Marshal(records)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s, err: %v", name, err)
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, name), bz, 0644)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Checksums[name] = checksum(bz)
+	}
+
+	bz, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, ManifestFile), bz, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ReadSnapshot reads the snapshot in dir, verifies the checksums of the files and verifies
+// the state root of the snapshot by rebuilding the account and nft trees.
+func ReadSnapshot(dir string) (*Manifest, *State, error) {
+	bz, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest := &Manifest{}
+	err = json.Unmarshal(bz, manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal manifest, err: %v", err)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, nil, fmt.Errorf("unsupported snapshot version %d", manifest.Version)
+	}
+
+	// snapshots of version 1 have no collections, the collection table can be filled by
+	// the migrate-collection command after they are imported
+	state := &State{}
+	for name, records := range state.files(manifest.Version) {
+		bz, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, nil, err
+		}
+		if checksum(bz) != manifest.Checksums[name] {
+			return nil, nil, fmt.Errorf("checksum of %s does not match the manifest", name)
+		}
+		err = json.Unmarshal(bz, records)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal %s, err: %v", name, err)
+		}
+	}
+	if state.Block == nil || state.Block.BlockHeight != manifest.BlockHeight {
+		return nil, nil, fmt.Errorf("block of the snapshot does not match height %d", manifest.BlockHeight)
+	}
+	if len(state.Accounts) != manifest.Accounts || len(state.Nfts) != manifest.Nfts ||
+		len(state.Collections) != manifest.Collections {
+		return nil, nil, fmt.Errorf("number of accounts, nfts or collections does not match the manifest")
+	}
+
+	accountRoot, nftRoot, err := ComputeRoots(state.Accounts, state.Nfts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if common.Bytes2Hex(accountRoot) != manifest.AccountRoot {
+		return nil, nil, fmt.Errorf("account root does not match the manifest")
+	}
+	if common.Bytes2Hex(nftRoot) != manifest.NftRoot {
+		return nil, nil, fmt.Errorf("nft root does not match the manifest")
+	}
+	stateRoot := common.Bytes2Hex(tree.ComputeStateRootHash(accountRoot, nftRoot))
+	if stateRoot != manifest.StateRoot || stateRoot != state.Block.StateRoot {
+		return nil, nil, fmt.Errorf("state root %s does not match the manifest", stateRoot)
+	}
+	return manifest, state, nil
+}
+
+// ComputeRoots rebuilds the account and nft trees of the state in memory and returns their roots.
+func ComputeRoots(accounts []*account.Account, nfts []*nft.L2Nft) (accountRoot []byte, nftRoot []byte, err error) {
+	accountTree, err := bsmt.NewBASSparseMerkleTree(newHasher(),
+		memory.NewMemoryDB(), tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, accountInfo := range accounts {
+		assetRoot, err := computeAssetRoot(accountInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashVal, err := tree.AccountToNode(
+			accountInfo.AccountNameHash,
+			accountInfo.PublicKey,
+			accountInfo.Nonce,
+			accountInfo.CollectionNonce,
+			assetRoot,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compute leaf of account %d, err: %v", accountInfo.AccountIndex, err)
+		}
+		err = accountTree.Set(uint64(accountInfo.AccountIndex), hashVal)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	nftTree, err := bsmt.NewBASSparseMerkleTree(newHasher(),
+		memory.NewMemoryDB(), tree.NftTreeHeight, tree.NilNftNodeHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, nftInfo := range nfts {
+		hashVal, err := tree.ComputeNftAssetLeafHash(
+			nftInfo.CreatorAccountIndex,
+			nftInfo.OwnerAccountIndex,
+			nftInfo.NftContentHash,
+			nftInfo.NftL1Address,
+			nftInfo.NftL1TokenId,
+			nftInfo.CreatorTreasuryRate,
+			nftInfo.CollectionId,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compute leaf of nft %d, err: %v", nftInfo.NftIndex, err)
+		}
+		err = nftTree.Set(uint64(nftInfo.NftIndex), hashVal)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return accountTree.Root(), nftTree.Root(), nil
+}
+
+func computeAssetRoot(accountInfo *account.Account) ([]byte, error) {
+	formatAccount, err := chain.ToFormatAccountInfo(accountInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse assets of account %d, err: %v", accountInfo.AccountIndex, err)
+	}
+	assetTree, err := tree.NewMemAccountAssetTree()
+	if err != nil {
+		return nil, err
+	}
+	for assetId, assetInfo := range formatAccount.AssetInfo {
+		hashVal, err := tree.AssetToNode(
+			assetInfo.Balance.String(),
+			assetInfo.OfferCanceledOrFinalized.String(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = assetTree.Set(uint64(assetId), hashVal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return assetTree.Root(), nil
+}
+
+func newHasher() *bsmt.Hasher {
+	return bsmt.NewHasherPool(func() hash.Hash { return mimc.NewMiMC() })
+}
+
+func checksum(bz []byte) string {
+	sum := sha256.Sum256(bz)
+	return hex.EncodeToString(sum[:])
+}
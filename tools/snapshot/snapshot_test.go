@@ -0,0 +1,136 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/collection"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/sysconfig"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+func testState(t *testing.T) *State {
+	state := &State{
+		Block: &block.Block{BlockHeight: 10},
+		Accounts: []*account.Account{
+			{
+				AccountIndex:    0,
+				AccountName:     "treasury.legend",
+				PublicKey:       "fcb8470d65c0ff52a1aa1d3fee3ee7ea2d0b9dd2da5c46a7f8d3f8d9b1d6a4a0",
+				AccountNameHash: "c0d201aace9a2c17ce7066dc6ffefaf7930f1317c4c95d0661b164a1c584d676",
+				Nonce:           1,
+				AssetInfo:       `{"0":{"AssetId":0,"Balance":100,"OfferCanceledOrFinalized":0}}`,
+			},
+		},
+		Nfts: []*nft.L2Nft{
+			{
+				NftIndex:            0,
+				CreatorAccountIndex: 0,
+				OwnerAccountIndex:   0,
+				NftContentHash:      "7eb9a41b3c6d8bf1a0fd4bd9f4d1b6f2d44e4a1c25e2b4d5e3b7a9c1d2e3f405",
+				NftL1Address:        "0",
+				NftL1TokenId:        "0",
+				CollectionId:        1,
+			},
+		},
+		SysConfigs:  []*sysconfig.SysConfig{{Name: "TreasuryAccountIndex", Value: "0"}},
+		Collections: []*collection.Collection{{CreatorAccountIndex: 0, CollectionId: 1, Name: "art", NftCount: 1}},
+	}
+	accountRoot, nftRoot, err := ComputeRoots(state.Accounts, state.Nfts)
+	require.NoError(t, err)
+	state.Block.StateRoot = common.Bytes2Hex(tree.ComputeStateRootHash(accountRoot, nftRoot))
+	return state
+}
+
+func TestComputeRootsOfEmptyState(t *testing.T) {
+	accountRoot, nftRoot, err := ComputeRoots(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tree.NilStateRoot, tree.ComputeStateRootHash(accountRoot, nftRoot))
+}
+
+func TestWriteAndReadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	state := testState(t)
+	written, err := WriteSnapshot(dir, state)
+	require.NoError(t, err)
+	assert.Equal(t, state.Block.StateRoot, written.StateRoot)
+
+	manifest, readState, err := ReadSnapshot(dir)
+	require.NoError(t, err)
+	assert.Equal(t, written, manifest)
+	assert.Equal(t, state.Accounts[0].AssetInfo, readState.Accounts[0].AssetInfo)
+	assert.Equal(t, state.Nfts[0].NftContentHash, readState.Nfts[0].NftContentHash)
+	assert.Equal(t, "TreasuryAccountIndex", readState.SysConfigs[0].Name)
+	assert.Equal(t, state.Collections, readState.Collections)
+}
+
+func TestReadSnapshotWithoutCollections(t *testing.T) {
+	dir := t.TempDir()
+	_, err := WriteSnapshot(dir, testState(t))
+	require.NoError(t, err)
+
+	// a snapshot of version 1 has no collection file
+	bz, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	manifest := &Manifest{}
+	require.NoError(t, json.Unmarshal(bz, manifest))
+	manifest.Version = 1
+	manifest.Collections = 0
+	delete(manifest.Checksums, CollectionFile)
+	bz, err = json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ManifestFile), bz, 0644))
+	require.NoError(t, os.Remove(filepath.Join(dir, CollectionFile)))
+
+	_, readState, err := ReadSnapshot(dir)
+	require.NoError(t, err)
+	assert.Empty(t, readState.Collections)
+}
+
+func TestWriteSnapshotWithWrongStateRoot(t *testing.T) {
+	state := testState(t)
+	state.Block.StateRoot = common.Bytes2Hex(tree.NilStateRoot)
+	_, err := WriteSnapshot(t.TempDir(), state)
+	assert.Error(t, err)
+}
+
+func TestReadTamperedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	_, err := WriteSnapshot(dir, testState(t))
+	require.NoError(t, err)
+
+	bz, err := ioutil.ReadFile(filepath.Join(dir, AccountFile))
+	require.NoError(t, err)
+	bz = bytes.Replace(bz, []byte(`"Nonce":1`), []byte(`"Nonce":2`), 1)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, AccountFile), bz, 0644))
+
+	_, _, err = ReadSnapshot(dir)
+	assert.ErrorContains(t, err, "checksum")
+}
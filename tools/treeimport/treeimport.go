@@ -0,0 +1,100 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package treeimport
+
+import (
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+type Config struct {
+	TreeDB struct {
+		Driver tree.Driver
+		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+	}
+	LogConf logx.LogConf
+}
+
+// ImportLevelDB copies the trees of all services in a leveldb file into the empty badgerdb
+// configured in the config file. The keys of both drivers are wrapped with the namespaces
+// the same way, so they are copied as they are and the trees keep all their versions.
+// Stop the services before running it, since leveldb is locked by the service.
+func ImportLevelDB(configFile string, levelDBFile string, batchSize int) error {
+	var c Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	if c.TreeDB.Driver != tree.BadgerDB {
+		return fmt.Errorf("trees can only be imported into the %s driver, driver: %s", tree.BadgerDB, c.TreeDB.Driver)
+	}
+	src, err := leveldb.OpenFile(levelDBFile, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return fmt.Errorf("failed to open leveldb, err: %v", err)
+	}
+	defer src.Close()
+	dst, err := tree.NewBadgerDB(&c.TreeDB.BadgerDBOption, false)
+	if err != nil {
+		return fmt.Errorf("failed to open badgerdb, err: %v", err)
+	}
+	defer dst.Close()
+
+	empty, err := dst.IsEmpty()
+	if err != nil {
+		return fmt.Errorf("failed to read badgerdb, err: %v", err)
+	}
+	if !empty {
+		return fmt.Errorf("badgerdb %s is not empty", c.TreeDB.BadgerDBOption.File)
+	}
+
+	it := src.NewIterator(nil, nil)
+	defer it.Release()
+	batch := dst.NewBatch()
+	imported := 0
+	for it.Next() {
+		err = batch.Set(it.Key(), it.Value())
+		if err != nil {
+			return err
+		}
+		imported++
+		if imported%batchSize != 0 {
+			continue
+		}
+		err = batch.Write()
+		if err != nil {
+			return fmt.Errorf("failed to write badgerdb, err: %v", err)
+		}
+		batch.Reset()
+		logx.Infof("imported %d keys", imported)
+	}
+	if err = it.Error(); err != nil {
+		return fmt.Errorf("failed to iterate leveldb, err: %v", err)
+	}
+	err = batch.Write()
+	if err != nil {
+		return fmt.Errorf("failed to write badgerdb, err: %v", err)
+	}
+	logx.Infof("imported %d keys from %s into %s", imported, levelDBFile, c.TreeDB.BadgerDBOption.File)
+	return nil
+}
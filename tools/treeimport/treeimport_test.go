@@ -0,0 +1,68 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package treeimport
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+func openAccountTree(t *testing.T, driver tree.Driver, file string) (*tree.Context, bsmt.SparseMerkleTree) {
+	ctx, err := tree.NewContext("committer", driver, false, 0,
+		&tree.LevelDBOption{File: file}, nil, &tree.BadgerDBOption{File: file})
+	require.NoError(t, err)
+	require.NoError(t, tree.SetupTreeDB(ctx))
+	accountTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), tree.SetNamespace(ctx, tree.AccountPrefix),
+		tree.AccountTreeHeight, tree.NilAccountNodeHash, ctx.Options(0)...)
+	require.NoError(t, err)
+	return ctx, accountTree
+}
+
+func TestImportLevelDB(t *testing.T) {
+	levelDBFile := filepath.Join(t.TempDir(), "leveldb")
+	ctx, accountTree := openAccountTree(t, tree.LevelDB, levelDBFile)
+	for height := 1; height <= 3; height++ {
+		require.NoError(t, accountTree.Set(uint64(height), tree.NilAccountAssetNodeHash))
+		_, err := accountTree.Commit(nil)
+		require.NoError(t, err)
+	}
+	root := accountTree.Root()
+	require.NoError(t, ctx.TreeDB.Close())
+
+	badgerDBFile := filepath.Join(t.TempDir(), "badgerdb")
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(fmt.Sprintf(
+		"TreeDB:\n  Driver: badgerdb\n  BadgerDBOption:\n    File: %s\nLogConf:\n  Level: error\n", badgerDBFile)), 0644))
+
+	require.NoError(t, ImportLevelDB(configFile, levelDBFile, 2))
+
+	ctx, accountTree = openAccountTree(t, tree.BadgerDB, badgerDBFile)
+	assert.Equal(t, bsmt.Version(3), accountTree.LatestVersion())
+	assert.Equal(t, root, accountTree.Root())
+	require.NoError(t, ctx.TreeDB.Close())
+
+	// the badgerdb is not empty anymore
+	assert.ErrorContains(t, ImportLevelDB(configFile, levelDBFile, 2), "not empty")
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package treeusage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	smtleveldb "github.com/bnb-chain/zkbnb-smt/database/leveldb"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+type Config struct {
+	TreeDB struct {
+		Driver tree.Driver
+		//nolint:staticcheck
+		LevelDBOption tree.LevelDBOption `json:",optional"`
+		//nolint:staticcheck
+		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+		//nolint:staticcheck
+		RoutinePoolSize int `json:",optional"`
+	}
+	LogConf logx.LogConf
+}
+
+// ReportTreeUsage reports the versions kept by the account and nft trees of a service, which
+// are the versions the trees can be rolled back to, and the storage used by each namespace.
+// Asset trees only keep their previous version, rolling back the trees restores them from
+// the account history in the database.
+// The storage is only reported for the leveldb and badgerdb drivers. Stop the service before
+// running it, since the files are locked by the service.
+func ReportTreeUsage(configFile string, serviceName string) error {
+	var c Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	if c.TreeDB.Driver == tree.MemoryDB {
+		return fmt.Errorf("trees of the memorydb driver are not persisted")
+	}
+	treeCtx, err := tree.NewContext(serviceName, c.TreeDB.Driver, false, c.TreeDB.RoutinePoolSize,
+		&c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption, &c.TreeDB.BadgerDBOption)
+	if err != nil {
+		return fmt.Errorf("failed to create tree context, err: %v", err)
+	}
+
+	var sizeOf func(prefix []byte) (int64, error)
+	switch c.TreeDB.Driver {
+	case tree.LevelDB:
+		db, err := leveldb.OpenFile(c.TreeDB.LevelDBOption.File, &opt.Options{ReadOnly: true})
+		if err != nil {
+			return fmt.Errorf("failed to open leveldb, err: %v", err)
+		}
+		defer db.Close()
+		treeCtx.TreeDB = smtleveldb.NewFromExistLevelDB(db)
+		sizeOf = func(prefix []byte) (int64, error) {
+			sizes, err := db.SizeOf([]util.Range{*util.BytesPrefix(prefix)})
+			return sizes.Sum(), err
+		}
+	case tree.BadgerDB:
+		db, err := tree.NewBadgerDB(&c.TreeDB.BadgerDBOption, true)
+		if err != nil {
+			return fmt.Errorf("failed to open badgerdb, err: %v", err)
+		}
+		defer db.Close()
+		treeCtx.TreeDB = db
+		sizeOf = db.SizeOf
+	default:
+		err = tree.SetupTreeDB(treeCtx)
+		if err != nil {
+			return fmt.Errorf("failed to setup tree db, err: %v", err)
+		}
+	}
+
+	for _, namespace := range []string{tree.AccountPrefix, tree.NFTPrefix, tree.AccountAssetPrefix} {
+		name := strings.TrimSuffix(namespace, ":")
+		if namespace != tree.AccountAssetPrefix {
+			versions, err := treeVersions(treeCtx, namespace)
+			if err != nil {
+				return err
+			}
+			logx.Infof("%s tree keeps versions %s", name, versions)
+		} else {
+			logx.Infof("%s trees keep their previous version, they are restored from the account history on rollbacks", name)
+		}
+		if sizeOf == nil {
+			continue
+		}
+		size, err := sizeOf([]byte(strings.Join([]string{serviceName, namespace}, ":")))
+		if err != nil {
+			return fmt.Errorf("failed to get size of namespace %s, err: %v", name, err)
+		}
+		logx.Infof("%s trees use %d bytes", name, size)
+	}
+	return nil
+}
+
+func treeVersions(treeCtx *tree.Context, namespace string) (string, error) {
+	treeHeight, nilHash := tree.AccountTreeHeight, tree.NilAccountNodeHash
+	if namespace == tree.NFTPrefix {
+		treeHeight, nilHash = tree.NftTreeHeight, tree.NilNftNodeHash
+	}
+	smt, err := bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+		tree.SetNamespace(treeCtx, namespace), uint8(treeHeight), nilHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to open tree %s, err: %v", namespace, err)
+	}
+	return fmt.Sprintf("from %d to %d", smt.RecentVersion(), smt.LatestVersion()), nil
+}
@@ -0,0 +1,412 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verifystate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/common/chain"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	SourceTree     = "tree"
+	SourceRedis    = "redis"
+	SourcePostgres = "postgres"
+)
+
+type Config struct {
+	Postgres struct {
+		DataSource string
+	}
+	CacheRedis cache.CacheConf
+	TreeDB     struct {
+		Driver tree.Driver
+		//nolint:staticcheck
+		LevelDBOption tree.LevelDBOption `json:",optional"`
+		//nolint:staticcheck
+		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		BadgerDBOption tree.BadgerDBOption `json:",optional"`
+		//nolint:staticcheck
+		RoutinePoolSize int `json:",optional"`
+	}
+	LogConf logx.LogConf
+}
+
+// Mismatch is a leaf whose hash in the tree db or redis cache differs from the hash computed
+// from postgres. AssetId is only set for the leaves of asset trees.
+type Mismatch struct {
+	Source   string
+	Leaf     string
+	Index    int64
+	AssetId  int64
+	Expected string
+	Actual   string
+}
+
+func (m *Mismatch) String() string {
+	if m.Leaf == "asset" {
+		return fmt.Sprintf("%s %s mismatch, account: %d, asset: %d, expected: %s, actual: %s",
+			m.Source, m.Leaf, m.Index, m.AssetId, m.Expected, m.Actual)
+	}
+	return fmt.Sprintf("%s %s mismatch, index: %d, expected: %s, actual: %s",
+		m.Source, m.Leaf, m.Index, m.Expected, m.Actual)
+}
+
+type verifier struct {
+	accountModel account.AccountModel
+	nftModel     nft.L2NftModel
+	redisCache   dbcache.Cache
+	treeCtx      *tree.Context
+	blockHeight  int64
+	batchSize    int
+
+	accountTree bsmt.SparseMerkleTree
+	nftTree     bsmt.SparseMerkleTree
+	mismatches  []*Mismatch
+}
+
+// VerifyState recomputes the account, asset and nft leaves from the account and l2_nft tables,
+// and compares them with the trees of the service in the tree db and with the redis cache.
+// Every mismatch is reported. Run it while the committer is stopped, otherwise the tables
+// may move ahead of the trees during the check.
+func VerifyState(configFile string, serviceName string, batchSize int) error {
+	var c Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return err
+	}
+	blockModel := block.NewBlockModel(db)
+	blockHeight, err := blockModel.GetCurrentBlockHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get current block height, err: %v", err)
+	}
+	currentBlock, err := blockModel.GetBlockByHeightWithoutTx(blockHeight)
+	if err != nil {
+		return fmt.Errorf("failed to get block %d, err: %v", blockHeight, err)
+	}
+	if currentBlock.BlockStatus == block.StatusProposing {
+		blockHeight--
+		currentBlock, err = blockModel.GetBlockByHeightWithoutTx(blockHeight)
+		if err != nil {
+			return fmt.Errorf("failed to get block %d, err: %v", blockHeight, err)
+		}
+	}
+
+	v := &verifier{
+		accountModel: account.NewAccountModel(db),
+		nftModel:     nft.NewL2NftModel(db),
+		blockHeight:  blockHeight,
+		batchSize:    batchSize,
+	}
+	if len(c.CacheRedis) > 0 {
+		v.redisCache = dbcache.NewRedisCache(c.CacheRedis[0].Host, c.CacheRedis[0].Pass, 15*time.Minute)
+	}
+	if c.TreeDB.Driver == tree.MemoryDB {
+		// The trees are rebuilt from postgres when the services start.
+		logx.Infof("tree db is in memory, skip checking the trees")
+	} else {
+		err = v.openTrees(serviceName, &c)
+		if err != nil {
+			return err
+		}
+		stateRoot := common.Bytes2Hex(tree.ComputeStateRootHash(v.accountTree.Root(), v.nftTree.Root()))
+		if v.accountTree.LatestVersion() == bsmt.Version(blockHeight) &&
+			v.nftTree.LatestVersion() == bsmt.Version(blockHeight) && stateRoot != currentBlock.StateRoot {
+			v.report(&Mismatch{Source: SourceTree, Leaf: "state root", Index: blockHeight,
+				Expected: currentBlock.StateRoot, Actual: stateRoot})
+		}
+	}
+
+	logx.Infof("verifying state of block %d", blockHeight)
+	err = v.verifyAccounts()
+	if err != nil {
+		return err
+	}
+	err = v.verifyNfts()
+	if err != nil {
+		return err
+	}
+	if len(v.mismatches) != 0 {
+		return fmt.Errorf("found %d mismatches in the state of block %d", len(v.mismatches), blockHeight)
+	}
+	logx.Infof("state of block %d is consistent", blockHeight)
+	return nil
+}
+
+func (v *verifier) openTrees(serviceName string, c *Config) (err error) {
+	v.treeCtx, err = tree.NewContext(serviceName, c.TreeDB.Driver, false, c.TreeDB.RoutinePoolSize,
+		&c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption, &c.TreeDB.BadgerDBOption)
+	if err != nil {
+		return fmt.Errorf("failed to create tree context, err: %v", err)
+	}
+	err = tree.SetupTreeDB(v.treeCtx)
+	if err != nil {
+		return fmt.Errorf("failed to setup tree db, err: %v", err)
+	}
+	v.accountTree, err = bsmt.NewBASSparseMerkleTree(v.treeCtx.Hasher(),
+		tree.SetNamespace(v.treeCtx, tree.AccountPrefix), tree.AccountTreeHeight, tree.NilAccountNodeHash,
+		v.treeCtx.Options(v.blockHeight)...)
+	if err != nil {
+		return fmt.Errorf("failed to open account tree, err: %v", err)
+	}
+	v.nftTree, err = bsmt.NewBASSparseMerkleTree(v.treeCtx.Hasher(),
+		tree.SetNamespace(v.treeCtx, tree.NFTPrefix), tree.NftTreeHeight, tree.NilNftNodeHash,
+		v.treeCtx.Options(v.blockHeight)...)
+	if err != nil {
+		return fmt.Errorf("failed to open nft tree, err: %v", err)
+	}
+	if v.accountTree.LatestVersion() < bsmt.Version(v.blockHeight) || v.nftTree.LatestVersion() < bsmt.Version(v.blockHeight) {
+		logx.Errorf("trees are behind block %d, account tree version: %d, nft tree version: %d",
+			v.blockHeight, v.accountTree.LatestVersion(), v.nftTree.LatestVersion())
+	}
+	return nil
+}
+
+func (v *verifier) report(mismatch *Mismatch) {
+	logx.Error(mismatch.String())
+	v.mismatches = append(v.mismatches, mismatch)
+}
+
+func (v *verifier) verifyAccounts() error {
+	for offset := int64(0); ; offset += int64(v.batchSize) {
+		accounts, err := v.accountModel.GetAccounts(v.batchSize, offset)
+		if err != nil {
+			if err == types.DbErrNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get accounts, err: %v", err)
+		}
+		for _, accountInfo := range accounts {
+			if accountInfo.Status != account.AccountStatusConfirmed {
+				continue
+			}
+			err = v.verifyAccount(accountInfo)
+			if err != nil {
+				return err
+			}
+		}
+		logx.Infof("verified %d accounts", offset+int64(len(accounts)))
+	}
+}
+
+func (v *verifier) verifyAccount(accountInfo *account.Account) error {
+	assetLeaves, assetRoot, accountLeaf, err := computeAccountLeaves(accountInfo)
+	if err != nil {
+		return err
+	}
+	index := accountInfo.AccountIndex
+	if accountInfo.AssetRoot != common.Bytes2Hex(assetRoot) {
+		v.report(&Mismatch{Source: SourcePostgres, Leaf: "asset root", Index: index,
+			Expected: common.Bytes2Hex(assetRoot), Actual: accountInfo.AssetRoot})
+	}
+
+	if v.accountTree != nil {
+		assetTree, err := bsmt.NewBASSparseMerkleTree(v.treeCtx.Hasher(),
+			tree.SetNamespace(v.treeCtx, tree.AccountAssetNamespace(index)), tree.AssetTreeHeight,
+			tree.NilAccountAssetNodeHash, v.treeCtx.Options(v.blockHeight)...)
+		if err != nil {
+			return fmt.Errorf("failed to open asset tree of account %d, err: %v", index, err)
+		}
+		for assetId, expected := range assetLeaves {
+			actual, err := getLeaf(assetTree, uint64(assetId), v.blockHeight, tree.NilAccountAssetNodeHash)
+			if err != nil {
+				return fmt.Errorf("failed to get asset %d of account %d from tree, err: %v", assetId, index, err)
+			}
+			if !bytes.Equal(expected, actual) {
+				v.report(&Mismatch{Source: SourceTree, Leaf: "asset", Index: index, AssetId: assetId,
+					Expected: common.Bytes2Hex(expected), Actual: common.Bytes2Hex(actual)})
+			}
+		}
+		actual, err := getLeaf(v.accountTree, uint64(index), v.blockHeight, tree.NilAccountNodeHash)
+		if err != nil {
+			return fmt.Errorf("failed to get account %d from tree, err: %v", index, err)
+		}
+		if !bytes.Equal(accountLeaf, actual) {
+			v.report(&Mismatch{Source: SourceTree, Leaf: "account", Index: index,
+				Expected: common.Bytes2Hex(accountLeaf), Actual: common.Bytes2Hex(actual)})
+		}
+	}
+
+	if v.redisCache != nil {
+		cached := &account.Account{}
+		_, err := v.redisCache.Get(context.Background(), dbcache.AccountKeyByIndex(index), cached)
+		if err != nil {
+			if dbcache.IsKeyNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get account %d from redis, err: %v", index, err)
+		}
+		_, _, actual, err := computeAccountLeaves(cached)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(accountLeaf, actual) {
+			v.report(&Mismatch{Source: SourceRedis, Leaf: "account", Index: index,
+				Expected: common.Bytes2Hex(accountLeaf), Actual: common.Bytes2Hex(actual)})
+		}
+	}
+	return nil
+}
+
+func (v *verifier) verifyNfts() error {
+	for offset := int64(0); ; offset += int64(v.batchSize) {
+		nfts, err := v.nftModel.GetNfts(v.batchSize, offset)
+		if err != nil {
+			if err == types.DbErrNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get nfts, err: %v", err)
+		}
+		for _, nftInfo := range nfts {
+			err = v.verifyNft(nftInfo)
+			if err != nil {
+				return err
+			}
+		}
+		logx.Infof("verified %d nfts", offset+int64(len(nfts)))
+	}
+}
+
+func (v *verifier) verifyNft(nftInfo *nft.L2Nft) error {
+	nftLeaf, err := computeNftLeaf(nftInfo)
+	if err != nil {
+		return err
+	}
+	index := nftInfo.NftIndex
+
+	if v.nftTree != nil {
+		actual, err := getLeaf(v.nftTree, uint64(index), v.blockHeight, tree.NilNftNodeHash)
+		if err != nil {
+			return fmt.Errorf("failed to get nft %d from tree, err: %v", index, err)
+		}
+		if !bytes.Equal(nftLeaf, actual) {
+			v.report(&Mismatch{Source: SourceTree, Leaf: "nft", Index: index,
+				Expected: common.Bytes2Hex(nftLeaf), Actual: common.Bytes2Hex(actual)})
+		}
+	}
+
+	if v.redisCache != nil {
+		cached := &nft.L2Nft{}
+		_, err := v.redisCache.Get(context.Background(), dbcache.NftKeyByIndex(index), cached)
+		if err != nil {
+			if dbcache.IsKeyNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get nft %d from redis, err: %v", index, err)
+		}
+		actual, err := computeNftLeaf(cached)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(nftLeaf, actual) {
+			v.report(&Mismatch{Source: SourceRedis, Leaf: "nft", Index: index,
+				Expected: common.Bytes2Hex(nftLeaf), Actual: common.Bytes2Hex(actual)})
+		}
+	}
+	return nil
+}
+
+// computeAccountLeaves returns the asset leaves, the asset root and the account leaf of an account.
+func computeAccountLeaves(accountInfo *account.Account) (map[int64][]byte, []byte, []byte, error) {
+	formatAccount, err := chain.ToFormatAccountInfo(accountInfo)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse assets of account %d, err: %v", accountInfo.AccountIndex, err)
+	}
+	assetTree, err := tree.NewMemAccountAssetTree()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	assetLeaves := make(map[int64][]byte, len(formatAccount.AssetInfo))
+	for assetId, assetInfo := range formatAccount.AssetInfo {
+		hashVal, err := tree.ComputeAccountAssetLeafHash(
+			assetInfo.Balance.String(),
+			assetInfo.OfferCanceledOrFinalized.String(),
+		)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to compute asset %d leaf of account %d, err: %v",
+				assetId, accountInfo.AccountIndex, err)
+		}
+		err = assetTree.Set(uint64(assetId), hashVal)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		assetLeaves[assetId] = hashVal
+	}
+	accountLeaf, err := tree.ComputeAccountLeafHash(
+		accountInfo.AccountNameHash,
+		accountInfo.PublicKey,
+		accountInfo.Nonce,
+		accountInfo.CollectionNonce,
+		assetTree.Root(),
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to compute leaf of account %d, err: %v", accountInfo.AccountIndex, err)
+	}
+	return assetLeaves, assetTree.Root(), accountLeaf, nil
+}
+
+func computeNftLeaf(nftInfo *nft.L2Nft) ([]byte, error) {
+	hashVal, err := tree.ComputeNftAssetLeafHash(
+		nftInfo.CreatorAccountIndex,
+		nftInfo.OwnerAccountIndex,
+		nftInfo.NftContentHash,
+		nftInfo.NftL1Address,
+		nftInfo.NftL1TokenId,
+		nftInfo.CreatorTreasuryRate,
+		nftInfo.CollectionId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute leaf of nft %d, err: %v", nftInfo.NftIndex, err)
+	}
+	return hashVal, nil
+}
+
+// getLeaf reads a leaf at the block height, or at the latest version of the tree if it is
+// not updated since then. Leaves which are never set are returned as the nil leaf.
+func getLeaf(t bsmt.SparseMerkleTree, key uint64, blockHeight int64, nilLeaf []byte) ([]byte, error) {
+	version := bsmt.Version(blockHeight)
+	if t.LatestVersion() < version {
+		version = t.LatestVersion()
+	}
+	leaf, err := t.Get(key, &version)
+	if err == bsmt.ErrEmptyRoot || err == bsmt.ErrNodeNotFound {
+		return nilLeaf, nil
+	}
+	return leaf, err
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verifystate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+func testAccount(assetInfo string) *account.Account {
+	return &account.Account{
+		AccountName:     "treasury.legend",
+		PublicKey:       "fcb8470d65c0ff52a1aa1d3fee3ee7ea2d0b9dd2da5c46a7f8d3f8d9b1d6a4a0",
+		AccountNameHash: "c0d201aace9a2c17ce7066dc6ffefaf7930f1317c4c95d0661b164a1c584d676",
+		AssetInfo:       assetInfo,
+	}
+}
+
+func TestComputeAccountLeaves(t *testing.T) {
+	assetLeaves, assetRoot, _, err := computeAccountLeaves(testAccount("{}"))
+	require.NoError(t, err)
+	assert.Empty(t, assetLeaves)
+	assert.Equal(t, tree.NilAccountAssetRoot, assetRoot)
+
+	assetLeaves, assetRoot, _, err = computeAccountLeaves(testAccount(
+		`{"1":{"AssetId":1,"Balance":100,"OfferCanceledOrFinalized":0}}`))
+	require.NoError(t, err)
+	expected, err := tree.ComputeAccountAssetLeafHash("100", "0")
+	require.NoError(t, err)
+	assert.Equal(t, expected, assetLeaves[1])
+	assert.NotEqual(t, tree.NilAccountAssetRoot, assetRoot)
+}
+
+func TestGetLeaf(t *testing.T) {
+	assetTree, err := tree.NewMemAccountAssetTree()
+	require.NoError(t, err)
+
+	leaf, err := getLeaf(assetTree, 1, 5, tree.NilAccountAssetNodeHash)
+	require.NoError(t, err)
+	assert.Equal(t, tree.NilAccountAssetNodeHash, leaf)
+
+	expected, err := tree.ComputeAccountAssetLeafHash("100", "0")
+	require.NoError(t, err)
+	require.NoError(t, assetTree.Set(1, expected))
+	_, err = assetTree.Commit(nil)
+	require.NoError(t, err)
+
+	// the tree is not updated since version 1
+	leaf, err = getLeaf(assetTree, 1, 5, tree.NilAccountAssetNodeHash)
+	require.NoError(t, err)
+	assert.Equal(t, expected, leaf)
+
+	leaf, err = getLeaf(assetTree, 2, 5, tree.NilAccountAssetNodeHash)
+	require.NoError(t, err)
+	assert.Equal(t, tree.NilAccountAssetNodeHash, leaf)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witnessmigration
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// MigrateBlockWitness re-encodes the json block witnesses with the binary encoding.
+// It can run while the witness and prover services are running, since the readers
+// accept both encodings. The witness service adds the binary witness column at
+// startup, so the services can be upgraded before or after the migration.
+func MigrateBlockWitness(dsn string, batchSize int) error {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	blockWitnessModel := blockwitness.NewBlockWitnessModel(db)
+
+	// add the binary witness column in case no upgraded witness service has run yet
+	err = blockWitnessModel.CreateBlockWitnessTable()
+	if err != nil {
+		return fmt.Errorf("failed to migrate block witness table, err: %v", err)
+	}
+
+	var fromHeight, migrated int64
+	for {
+		witnesses, err := blockWitnessModel.GetJsonBlockWitnesses(fromHeight, batchSize)
+		if err != nil {
+			if err == types.DbErrNotFound {
+				break
+			}
+			return err
+		}
+		for _, witness := range witnesses {
+			cryptoBlock, err := prove.ParseBlockWitness(witness)
+			if err != nil {
+				return fmt.Errorf("failed to parse block witness %d, err: %v", witness.Height, err)
+			}
+			bz, err := prove.EncodeBlockWitness(cryptoBlock)
+			if err != nil {
+				return fmt.Errorf("failed to encode block witness %d, err: %v", witness.Height, err)
+			}
+			witness.WitnessData = ""
+			witness.WitnessBinary = bz
+			err = blockWitnessModel.UpdateBlockWitnessData(witness)
+			if err != nil {
+				return fmt.Errorf("failed to update block witness %d, err: %v", witness.Height, err)
+			}
+			fromHeight = witness.Height + 1
+			migrated++
+		}
+		logx.Infof("migrated %d block witnesses, next height: %d", migrated, fromHeight)
+	}
+
+	logx.Infof("block witness migration is done, %d block witnesses are migrated", migrated)
+	return nil
+}
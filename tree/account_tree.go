@@ -21,6 +21,7 @@ import (
 	"errors"
 	"hash"
 	"strconv"
+	"time"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -32,7 +33,7 @@ import (
 	"github.com/bnb-chain/zkbnb/types"
 )
 
-func accountAssetNamespace(index int64) string {
+func AccountAssetNamespace(index int64) string {
 	return AccountAssetPrefix + strconv.Itoa(int(index)) + ":"
 }
 
@@ -56,7 +57,7 @@ func InitAccountTree(
 	// init account state trees
 	accountAssetTrees = NewLazyTreeCache(assetCacheSize, accountNums-1, blockHeight, func(index, block int64) bsmt.SparseMerkleTree {
 		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
-			SetNamespace(ctx, accountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
+			SetNamespace(ctx, AccountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
 			ctx.Options(block)...)
 		if err != nil {
 			logx.Errorf("unable to create new tree by assets: %s", err.Error())
@@ -111,23 +112,32 @@ func InitAccountTree(
 			logx.Errorf("unable to rollback account tree: %s, version: %d", err.Error(), blockHeight)
 			return nil, nil, err
 		}
-	}
-
-	for i := int64(0); i < accountNums; i++ {
-		asset := accountAssetTrees.Get(i)
-		if asset.LatestVersion() > bsmt.Version(blockHeight) && !asset.IsEmpty() {
-			logx.Infof("asset tree %d version [%d] is higher than block, rollback to %d", i, asset.LatestVersion(), blockHeight)
-			err := asset.Rollback(bsmt.Version(blockHeight))
-			if err != nil {
-				logx.Errorf("unable to rollback asset [%d] tree: %s, version: %d", i, err.Error(), blockHeight)
-				return nil, nil, err
-			}
+		// asset trees are not versioned by block height, restore the ones changed after the block
+		err = RestoreAssetTrees(accountModel, accountHistoryModel, blockHeight, accountAssetTrees)
+		if err != nil {
+			logx.Errorf("unable to restore asset trees: %s, version: %d", err.Error(), blockHeight)
+			return nil, nil, err
 		}
 	}
 
 	return accountTree, accountAssetTrees, nil
 }
 
+// WarmUpAssetTrees loads the asset trees of up to warmUpSize most recently active accounts
+// into the cache, they are the ones most likely to be changed by the next blocks.
+func WarmUpAssetTrees(accountHistoryModel account.AccountHistoryModel, blockHeight int64, warmUpSize int,
+	accountAssetTrees *AssetTreeCache) error {
+	start := time.Now()
+	indexes, err := accountHistoryModel.GetRecentActiveAccountIndexes(blockHeight, warmUpSize)
+	if err != nil {
+		logx.Errorf("unable to get recent active accounts: %s", err.Error())
+		return err
+	}
+	accountAssetTrees.WarmUp(indexes)
+	logx.Infof("asset trees of %d recent active accounts are loaded, cost: %v", len(indexes), time.Since(start))
+	return nil
+}
+
 func reloadAccountTreeFromRDB(
 	accountModel account.AccountModel,
 	accountHistoryModel account.AccountHistoryModel,
@@ -182,46 +192,56 @@ func reloadAccountTreeFromRDB(
 			logx.Errorf("invalid account index")
 			return errors.New("invalid account index")
 		}
-		oAccountInfo := accountInfoMap[accountIndex]
-		accountInfo, err := chain.ToFormatAccountInfo(oAccountInfo)
+		err = setAccount(accountInfoMap[accountIndex], accountTree, accountAssetTrees)
 		if err != nil {
-			logx.Errorf("unable to convert to format account info: %s", err.Error())
 			return err
 		}
-		// create account assets node
-		for assetId, assetInfo := range accountInfo.AssetInfo {
-			hashVal, err := AssetToNode(
-				assetInfo.Balance.String(),
-				assetInfo.OfferCanceledOrFinalized.String(),
-			)
-			if err != nil {
-				logx.Errorf("unable to convert asset to node: %s", err.Error())
-				return err
-			}
-			err = accountAssetTrees.Get(accountIndex).Set(uint64(assetId), hashVal)
-			if err != nil {
-				logx.Errorf("unable to set asset to tree: %s", err.Error())
-				return err
-			}
-		}
-		accountHashVal, err := AccountToNode(
-			accountInfoMap[accountIndex].AccountNameHash,
-			accountInfoMap[accountIndex].PublicKey,
-			accountInfoMap[accountIndex].Nonce,
-			accountInfoMap[accountIndex].CollectionNonce,
-			accountAssetTrees.Get(accountIndex).Root(),
+	}
+
+	return nil
+}
+
+// setAccount sets the assets of the account to its asset tree and the account to the
+// account tree, the trees are not committed.
+func setAccount(oAccountInfo *account.Account, accountTree bsmt.SparseMerkleTree, accountAssetTrees *AssetTreeCache) error {
+	accountIndex := oAccountInfo.AccountIndex
+	accountInfo, err := chain.ToFormatAccountInfo(oAccountInfo)
+	if err != nil {
+		logx.Errorf("unable to convert to format account info: %s", err.Error())
+		return err
+	}
+	// create account assets node
+	for assetId, assetInfo := range accountInfo.AssetInfo {
+		hashVal, err := AssetToNode(
+			assetInfo.Balance.String(),
+			assetInfo.OfferCanceledOrFinalized.String(),
 		)
 		if err != nil {
-			logx.Errorf("unable to convert account to node: %s", err.Error())
+			logx.Errorf("unable to convert asset to node: %s", err.Error())
 			return err
 		}
-		err = accountTree.Set(uint64(accountIndex), accountHashVal)
+		err = accountAssetTrees.Get(accountIndex).Set(uint64(assetId), hashVal)
 		if err != nil {
-			logx.Errorf("unable to set account to tree: %s", err.Error())
+			logx.Errorf("unable to set asset to tree: %s", err.Error())
 			return err
 		}
 	}
-
+	accountHashVal, err := AccountToNode(
+		oAccountInfo.AccountNameHash,
+		oAccountInfo.PublicKey,
+		oAccountInfo.Nonce,
+		oAccountInfo.CollectionNonce,
+		accountAssetTrees.Get(accountIndex).Root(),
+	)
+	if err != nil {
+		logx.Errorf("unable to convert account to node: %s", err.Error())
+		return err
+	}
+	err = accountTree.Set(uint64(accountIndex), accountHashVal)
+	if err != nil {
+		logx.Errorf("unable to set account to tree: %s", err.Error())
+		return err
+	}
 	return nil
 }
 
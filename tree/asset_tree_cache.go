@@ -1,13 +1,58 @@
 package tree
 
 import (
+	"fmt"
 	"sync"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
 
 	bsmt "github.com/bnb-chain/zkbnb-smt"
 )
 
+var (
+	assetTreeCacheHitMetrics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "asset_tree_cache_hit",
+		Help:      "number of asset trees found in the cache",
+	})
+	assetTreeCacheMissMetrics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "asset_tree_cache_miss",
+		Help:      "number of asset trees rebuilt on cache misses",
+	})
+	assetTreeCacheEvictionMetrics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "asset_tree_cache_eviction",
+		Help:      "number of asset trees evicted from the cache",
+	})
+	assetTreeRebuildMetrics = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "zkbnb",
+		Name:      "asset_tree_rebuild_seconds",
+		Help:      "latency of rebuilding an asset tree on a cache miss",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	})
+)
+
+// RegisterAssetTreeCacheMetrics registers the metrics of the asset tree caches, it is called
+// once by the services which expose metrics.
+func RegisterAssetTreeCacheMetrics() error {
+	if err := prometheus.Register(assetTreeCacheHitMetrics); err != nil {
+		return fmt.Errorf("prometheus.Register assetTreeCacheHitMetrics error: %v", err)
+	}
+	if err := prometheus.Register(assetTreeCacheMissMetrics); err != nil {
+		return fmt.Errorf("prometheus.Register assetTreeCacheMissMetrics error: %v", err)
+	}
+	if err := prometheus.Register(assetTreeCacheEvictionMetrics); err != nil {
+		return fmt.Errorf("prometheus.Register assetTreeCacheEvictionMetrics error: %v", err)
+	}
+	if err := prometheus.Register(assetTreeRebuildMetrics); err != nil {
+		return fmt.Errorf("prometheus.Register assetTreeRebuildMetrics error: %v", err)
+	}
+	return nil
+}
+
 // Lazy init cache for asset trees
 type AssetTreeCache struct {
 	initFunction      func(index, block int64) bsmt.SparseMerkleTree
@@ -17,13 +62,14 @@ type AssetTreeCache struct {
 	changes           map[int64]bool
 	changesLock       sync.RWMutex
 	treeCache         *lru.Cache
+	maxSize           int
 }
 
 // Creates new AssetTreeCache
 // maxSize defines the maximum size of currently initialized trees
 // accountNumber defines the number of accounts to create/or next index for new account
 func NewLazyTreeCache(maxSize int, accountNumber int64, blockNumber int64, f func(index, block int64) bsmt.SparseMerkleTree) *AssetTreeCache {
-	cache := AssetTreeCache{initFunction: f, nextAccountNumber: accountNumber, blockNumber: blockNumber, changes: make(map[int64]bool, maxSize*10)}
+	cache := AssetTreeCache{initFunction: f, nextAccountNumber: accountNumber, blockNumber: blockNumber, changes: make(map[int64]bool, maxSize*10), maxSize: maxSize}
 	cache.treeCache, _ = lru.NewWithEvict(maxSize, cache.onDelete)
 	return &cache
 }
@@ -49,8 +95,15 @@ func (c *AssetTreeCache) GetNextAccountIndex() int64 {
 
 // Returns asset tree based on account index
 func (c *AssetTreeCache) Get(i int64) (tree bsmt.SparseMerkleTree) {
+	if tmpTree, ok := c.treeCache.Get(i); ok {
+		assetTreeCacheHitMetrics.Inc()
+		return tmpTree.(bsmt.SparseMerkleTree)
+	}
+	assetTreeCacheMissMetrics.Inc()
 	c.mainLock.RLock()
+	start := time.Now()
 	c.treeCache.ContainsOrAdd(i, c.initFunction(i, c.blockNumber))
+	assetTreeRebuildMetrics.Observe(time.Since(start).Seconds())
 	c.mainLock.RUnlock()
 	if tmpTree, ok := c.treeCache.Get(i); ok {
 		tree = tmpTree.(bsmt.SparseMerkleTree)
@@ -58,6 +111,17 @@ func (c *AssetTreeCache) Get(i int64) (tree bsmt.SparseMerkleTree) {
 	return
 }
 
+// Loads the asset trees of the accounts into the cache, the ones at the front are kept
+// longest. Accounts beyond the size of the cache are skipped.
+func (c *AssetTreeCache) WarmUp(indexes []int64) {
+	if len(indexes) > c.maxSize {
+		indexes = indexes[:c.maxSize]
+	}
+	for i := len(indexes) - 1; i >= 0; i-- {
+		c.Get(indexes[i])
+	}
+}
+
 // Returns slice of indexes of asset trees that were changned
 func (c *AssetTreeCache) GetChanges() []int64 {
 	c.mainLock.Lock()
@@ -86,6 +150,7 @@ func (c *AssetTreeCache) CleanChanges() {
 
 // Internal method to that marks if changes happend to tree eviced from LRU
 func (c *AssetTreeCache) onDelete(k, v interface{}) {
+	assetTreeCacheEvictionMetrics.Inc()
 	c.changesLock.Lock()
 	if v.(bsmt.SparseMerkleTree).LatestVersion()-v.(bsmt.SparseMerkleTree).RecentVersion() > 1 {
 		c.changes[k.(int64)] = true
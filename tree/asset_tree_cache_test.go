@@ -0,0 +1,50 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+)
+
+func TestAssetTreeCacheRebuildsOnMissOnly(t *testing.T) {
+	rebuilds := map[int64]int{}
+	cache := NewLazyTreeCache(2, 0, 0, func(index, block int64) bsmt.SparseMerkleTree {
+		rebuilds[index]++
+		tree, err := NewMemAccountAssetTree()
+		require.NoError(t, err)
+		return tree
+	})
+	hits := testutil.ToFloat64(assetTreeCacheHitMetrics)
+	misses := testutil.ToFloat64(assetTreeCacheMissMetrics)
+	evictions := testutil.ToFloat64(assetTreeCacheEvictionMetrics)
+
+	cache.Get(1)
+	cache.Get(1)
+	cache.Get(2)
+	cache.Get(3)
+	assert.Equal(t, map[int64]int{1: 1, 2: 1, 3: 1}, rebuilds)
+	assert.Equal(t, hits+1, testutil.ToFloat64(assetTreeCacheHitMetrics))
+	assert.Equal(t, misses+3, testutil.ToFloat64(assetTreeCacheMissMetrics))
+	assert.Equal(t, evictions+1, testutil.ToFloat64(assetTreeCacheEvictionMetrics))
+}
+
+func TestAssetTreeCacheWarmUp(t *testing.T) {
+	cache := NewLazyTreeCache(2, 0, 0, func(index, block int64) bsmt.SparseMerkleTree {
+		tree, err := NewMemAccountAssetTree()
+		require.NoError(t, err)
+		return tree
+	})
+	cache.WarmUp([]int64{5, 4, 3})
+	assert.True(t, cache.treeCache.Contains(int64(5)))
+	assert.True(t, cache.treeCache.Contains(int64(4)))
+	assert.False(t, cache.treeCache.Contains(int64(3)))
+
+	// the most recent account is evicted last
+	cache.Get(6)
+	assert.True(t, cache.treeCache.Contains(int64(5)))
+	assert.False(t, cache.treeCache.Contains(int64(4)))
+}
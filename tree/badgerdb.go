@@ -0,0 +1,325 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb-smt/database"
+)
+
+var (
+	_ database.TreeDB  = (*BadgerDatabase)(nil)
+	_ database.Batcher = (*badgerBatch)(nil)
+)
+
+const (
+	defaultBadgerMemTableSize       = 64
+	defaultBadgerValueLogGCInterval = 10 * time.Minute
+	badgerValueLogGCDiscardRatio    = 0.5
+	// Values up to this size are kept in the LSM tree instead of the value log, tree nodes
+	// are always smaller, so the value log is only used as the write-ahead log.
+	badgerValueThreshold = 65500
+)
+
+type BadgerDBOption struct {
+	File string
+	// Size of a memtable in megabytes, default is 64.
+	//nolint:staticcheck
+	MemTableSize int `json:",optional"`
+	//nolint:staticcheck
+	NumMemtables int `json:",optional"`
+	// Number of level 0 tables at which writes are stalled until compaction catches up.
+	//nolint:staticcheck
+	NumLevelZeroTablesStall int `json:",optional"`
+	//nolint:staticcheck
+	NumCompactors int `json:",optional"`
+	//nolint:staticcheck
+	SyncWrites bool `json:",optional"`
+	// Interval of the value log garbage collection, default is 10 minutes.
+	//nolint:staticcheck
+	ValueLogGCInterval time.Duration `json:",optional"`
+}
+
+// BadgerDatabase is a tree db backed by badger, an LSM tree store which keeps compaction
+// off the write path. Keys are wrapped with the namespace the same way as the leveldb
+// driver, so the keys of a leveldb file can be copied as they are.
+type BadgerDatabase struct {
+	namespace []byte
+	db        *badger.DB
+	stop      chan struct{}
+	stopOnce  *sync.Once
+}
+
+func NewBadgerDB(option *BadgerDBOption, readonly bool) (*BadgerDatabase, error) {
+	options := badger.DefaultOptions(option.File).
+		WithValueThreshold(badgerValueThreshold).
+		WithSyncWrites(option.SyncWrites).
+		WithReadOnly(readonly).
+		WithLogger(badgerLogger{})
+	memTableSize := option.MemTableSize
+	if memTableSize <= 0 {
+		memTableSize = defaultBadgerMemTableSize
+	}
+	options = options.WithMemTableSize(int64(memTableSize) << 20)
+	if option.NumMemtables > 0 {
+		options = options.WithNumMemtables(option.NumMemtables)
+	}
+	if option.NumLevelZeroTablesStall > 0 {
+		options = options.WithNumLevelZeroTablesStall(option.NumLevelZeroTablesStall)
+	}
+	if option.NumCompactors > 0 {
+		options = options.WithNumCompactors(option.NumCompactors)
+	}
+
+	db, err := badger.Open(options)
+	if err != nil {
+		return nil, err
+	}
+	bdb := &BadgerDatabase{
+		db:       db,
+		stop:     make(chan struct{}),
+		stopOnce: &sync.Once{},
+	}
+	if !readonly {
+		interval := option.ValueLogGCInterval
+		if interval <= 0 {
+			interval = defaultBadgerValueLogGCInterval
+		}
+		go bdb.runValueLogGC(interval)
+	}
+	return bdb, nil
+}
+
+// WrapBadgerDBWithNamespace returns a badger db sharing the same store, whose keys are
+// prefixed with the namespace.
+func WrapBadgerDBWithNamespace(db *BadgerDatabase, namespace string) *BadgerDatabase {
+	return &BadgerDatabase{
+		namespace: []byte(namespace),
+		db:        db.db,
+		stop:      db.stop,
+		stopOnce:  db.stopOnce,
+	}
+}
+
+func (db *BadgerDatabase) runValueLogGC(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-db.stop:
+			return
+		case <-ticker.C:
+			// Rewrite value log files until there is nothing left to reclaim.
+			for db.db.RunValueLogGC(badgerValueLogGCDiscardRatio) == nil {
+			}
+		}
+	}
+}
+
+func (db *BadgerDatabase) wrapKey(key []byte) []byte {
+	if len(db.namespace) > 0 {
+		return bytes.Join([][]byte{db.namespace, key}, []byte(":"))
+	}
+	return key
+}
+
+func (db *BadgerDatabase) Close() error {
+	db.stopOnce.Do(func() { close(db.stop) })
+	return db.db.Close()
+}
+
+func (db *BadgerDatabase) Has(key []byte) (bool, error) {
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(db.wrapKey(key))
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (db *BadgerDatabase) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(db.wrapKey(key))
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, database.ErrDatabaseNotFound
+	}
+	return value, err
+}
+
+func (db *BadgerDatabase) Set(key []byte, value []byte) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(db.wrapKey(key), value)
+	})
+}
+
+func (db *BadgerDatabase) Delete(key []byte) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(db.wrapKey(key))
+	})
+}
+
+func (db *BadgerDatabase) NewBatch() database.Batcher {
+	return &badgerBatch{db: db}
+}
+
+// Size returns the sizes of the LSM tree and of the value log of the store.
+func (db *BadgerDatabase) Size() (lsm int64, vlog int64) {
+	return db.db.Size()
+}
+
+// SizeOf returns the estimated size of the keys and values with the prefix.
+func (db *BadgerDatabase) SizeOf(prefix []byte) (int64, error) {
+	var size int64
+	err := db.db.View(func(txn *badger.Txn) error {
+		options := badger.DefaultIteratorOptions
+		options.PrefetchValues = false
+		options.Prefix = db.wrapKey(prefix)
+		it := txn.NewIterator(options)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			size += it.Item().EstimatedSize()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Iterate calls fn with the keys with the prefix in order, starting from the start key.
+// The prefix and the start key are wrapped with the namespace of the db.
+func (db *BadgerDatabase) Iterate(prefix []byte, start []byte, fn func(key, value []byte) error) error {
+	return db.db.View(func(txn *badger.Txn) error {
+		options := badger.DefaultIteratorOptions
+		options.Prefix = db.wrapKey(prefix)
+		it := txn.NewIterator(options)
+		defer it.Close()
+		for it.Seek(db.wrapKey(start)); it.Valid(); it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			err = fn(it.Item().KeyCopy(nil), value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IsEmpty returns whether there is no key in the namespace of the db.
+func (db *BadgerDatabase) IsEmpty() (bool, error) {
+	empty := true
+	err := db.db.View(func(txn *badger.Txn) error {
+		options := badger.DefaultIteratorOptions
+		options.PrefetchValues = false
+		options.Prefix = db.namespace
+		it := txn.NewIterator(options)
+		defer it.Close()
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	return empty, err
+}
+
+type badgerBatchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// badgerBatch buffers the changes until Write is called, badger write batches can not be
+// reused after they are flushed. A batch cannot be used concurrently.
+type badgerBatch struct {
+	db   *BadgerDatabase
+	ops  []badgerBatchOp
+	size int
+}
+
+func (b *badgerBatch) Set(key, value []byte) error {
+	b.ops = append(b.ops, badgerBatchOp{
+		key:   b.db.wrapKey(common.CopyBytes(key)),
+		value: common.CopyBytes(value),
+	})
+	b.size += len(value)
+	return nil
+}
+
+func (b *badgerBatch) Delete(key []byte) error {
+	b.ops = append(b.ops, badgerBatchOp{key: b.db.wrapKey(common.CopyBytes(key)), delete: true})
+	b.size += len(key)
+	return nil
+}
+
+func (b *badgerBatch) Write() error {
+	wb := b.db.db.NewWriteBatch()
+	for _, op := range b.ops {
+		var err error
+		if op.delete {
+			err = wb.Delete(op.key)
+		} else {
+			err = wb.Set(op.key, op.value)
+		}
+		if err != nil {
+			wb.Cancel()
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+func (b *badgerBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *badgerBatch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
+}
+
+type badgerLogger struct{}
+
+func (badgerLogger) Errorf(format string, v ...interface{}) {
+	logx.Errorf("badger: "+format, v...)
+}
+
+func (badgerLogger) Warningf(format string, v ...interface{}) {
+	logx.Infof("badger: "+format, v...)
+}
+
+func (badgerLogger) Infof(format string, v ...interface{}) {
+	logx.Infof("badger: "+format, v...)
+}
+
+func (badgerLogger) Debugf(string, ...interface{}) {}
@@ -0,0 +1,59 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database"
+	"github.com/bnb-chain/zkbnb-smt/database/dbtest"
+)
+
+func TestBadgerDB(t *testing.T) {
+	dbtest.TestDatabaseSuite(t, func() database.TreeDB {
+		db, err := NewBadgerDB(&BadgerDBOption{File: t.TempDir()}, false)
+		require.NoError(t, err)
+		return db
+	})
+}
+
+func TestBadgerDBWithNamespace(t *testing.T) {
+	dbtest.TestDatabaseSuite(t, func() database.TreeDB {
+		db, err := NewBadgerDB(&BadgerDBOption{File: t.TempDir()}, false)
+		require.NoError(t, err)
+		return WrapBadgerDBWithNamespace(db, "test")
+	})
+}
+
+func TestBadgerDBReopenTree(t *testing.T) {
+	option := &BadgerDBOption{File: t.TempDir()}
+	openTree := func() (*Context, bsmt.SparseMerkleTree) {
+		ctx, err := NewContext("test", BadgerDB, false, 0, nil, nil, option)
+		require.NoError(t, err)
+		require.NoError(t, SetupTreeDB(ctx))
+		accountTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), SetNamespace(ctx, AccountPrefix),
+			AccountTreeHeight, NilAccountNodeHash, ctx.Options(0)...)
+		require.NoError(t, err)
+		return ctx, accountTree
+	}
+
+	ctx, accountTree := openTree()
+	for height := 1; height <= 3; height++ {
+		require.NoError(t, accountTree.Set(uint64(height), NilAccountAssetNodeHash))
+		_, err := accountTree.Commit(nil)
+		require.NoError(t, err)
+	}
+	root := accountTree.Root()
+	db := ctx.TreeDB.(*BadgerDatabase)
+	size, err := db.SizeOf([]byte("test:" + AccountPrefix))
+	require.NoError(t, err)
+	assert.Greater(t, size, int64(0))
+	require.NoError(t, ctx.TreeDB.Close())
+
+	ctx, accountTree = openTree()
+	defer ctx.TreeDB.Close()
+	assert.Equal(t, bsmt.Version(3), accountTree.LatestVersion())
+	assert.Equal(t, root, accountTree.Root())
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/common/chain"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// ReplayTreesFromLatestVersion recovers the trees on disk to blockHeight by rolling them back
+// to the newest version consistent with the blocks and replaying the histories above it. It
+// returns false without changing the trees if there is no tree version on disk, in which case
+// the trees have to be reloaded from the full histories.
+func ReplayTreesFromLatestVersion(
+	accountModel account.AccountModel,
+	accountHistoryModel account.AccountHistoryModel,
+	nftHistoryModel nft.L2NftHistoryModel,
+	blockModel block.BlockModel,
+	blockHeight int64,
+	ctx *Context,
+	assetCacheSize int,
+) (bool, error) {
+	accountTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+		SetNamespace(ctx, AccountPrefix), AccountTreeHeight, NilAccountNodeHash,
+		ctx.Options(0)...)
+	if err != nil {
+		return false, fmt.Errorf("unable to create account tree: %v", err)
+	}
+	nftTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+		SetNamespace(ctx, NFTPrefix), NftTreeHeight, NilNftNodeHash,
+		ctx.Options(0)...)
+	if err != nil {
+		return false, fmt.Errorf("unable to create nft tree: %v", err)
+	}
+	if accountTree.LatestVersion() == 0 && nftTree.LatestVersion() == 0 {
+		return false, nil
+	}
+
+	version, err := RollbackToConsistentVersion(blockModel, blockHeight, accountTree, nftTree)
+	if err != nil {
+		return false, err
+	}
+	logx.Infof("tree version %d is consistent with the blocks, replay the histories to block %d", version, blockHeight)
+
+	accountAssetTrees := NewLazyTreeCache(assetCacheSize, 0, version, func(index, block int64) bsmt.SparseMerkleTree {
+		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+			SetNamespace(ctx, AccountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
+			ctx.Options(block)...)
+		if err != nil {
+			logx.Errorf("unable to create new tree by assets: %s", err.Error())
+			panic(err.Error())
+		}
+		return tree
+	})
+	err = RestoreAssetTrees(accountModel, accountHistoryModel, version, accountAssetTrees)
+	if err != nil {
+		return false, err
+	}
+	err = ReplayTrees(accountModel, accountHistoryModel, nftHistoryModel, version, blockHeight, ctx.BatchReloadSize(),
+		accountTree, accountAssetTrees, nftTree)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RollbackToConsistentVersion rolls the account and nft trees back to the newest version not
+// above blockHeight whose state root matches the state root of the block, and returns it.
+// Versions older than the ones kept by the trees can not be checked.
+func RollbackToConsistentVersion(
+	blockModel block.BlockModel,
+	blockHeight int64,
+	accountTree bsmt.SparseMerkleTree,
+	nftTree bsmt.SparseMerkleTree,
+) (int64, error) {
+	version := blockHeight
+	if int64(accountTree.LatestVersion()) < version {
+		version = int64(accountTree.LatestVersion())
+	}
+	if int64(nftTree.LatestVersion()) < version {
+		version = int64(nftTree.LatestVersion())
+	}
+	oldest := int64(accountTree.RecentVersion())
+	if int64(nftTree.RecentVersion()) > oldest {
+		oldest = int64(nftTree.RecentVersion())
+	}
+
+	for ; version >= oldest; version-- {
+		for _, smt := range []bsmt.SparseMerkleTree{accountTree, nftTree} {
+			if smt.LatestVersion() > bsmt.Version(version) && !smt.IsEmpty() {
+				err := smt.Rollback(bsmt.Version(version))
+				if err != nil {
+					return 0, fmt.Errorf("unable to rollback tree to version %d: %v", version, err)
+				}
+			}
+		}
+		stateBlock, err := blockModel.GetBlockByHeightWithoutTx(version)
+		if err != nil {
+			return 0, fmt.Errorf("unable to get block %d: %v", version, err)
+		}
+		if common.Bytes2Hex(ComputeStateRootHash(accountTree.Root(), nftTree.Root())) == stateBlock.StateRoot {
+			return version, nil
+		}
+		logx.Infof("state root of tree version %d mismatches the block", version)
+	}
+	return 0, fmt.Errorf("no tree version from %d to %d matches the state root of the block", oldest, blockHeight)
+}
+
+// RestoreAssetTrees makes the asset trees of the accounts changed after the height match the
+// asset roots at the height. An asset tree only keeps its previous version, so it is rolled
+// back if the account is changed once after the height, and its leaves are set back to the
+// assets at the height otherwise.
+func RestoreAssetTrees(
+	accountModel account.AccountModel,
+	accountHistoryModel account.AccountHistoryModel,
+	blockHeight int64,
+	accountAssetTrees *AssetTreeCache,
+) error {
+	indexes, err := accountHistoryModel.GetAccountIndexesChangedAfter(blockHeight)
+	if err != nil {
+		return fmt.Errorf("unable to get accounts changed after block %d: %v", blockHeight, err)
+	}
+	for _, index := range indexes {
+		expected := NilAccountAssetRoot
+		assetInfo := "{}"
+		accountHistory, err := accountHistoryModel.GetLatestAccountHistory(index, blockHeight+1)
+		if err != nil && err != types.DbErrNotFound {
+			return fmt.Errorf("unable to get history of account %d: %v", index, err)
+		}
+		if err == nil {
+			expected = common.FromHex(accountHistory.AssetRoot)
+			assetInfo = accountHistory.AssetInfo
+		}
+		assetTree := accountAssetTrees.Get(index)
+		if bytes.Equal(assetTree.Root(), expected) {
+			continue
+		}
+		if assetTree.LatestVersion() > assetTree.RecentVersion() && !assetTree.IsEmpty() {
+			err = assetTree.Rollback(assetTree.LatestVersion() - 1)
+			if err != nil {
+				return fmt.Errorf("unable to rollback asset tree %d: %v", index, err)
+			}
+			if bytes.Equal(assetTree.Root(), expected) {
+				logx.Infof("asset tree %d is rolled back to version %d", index, assetTree.LatestVersion())
+				continue
+			}
+		}
+
+		err = resetAssets(accountModel, index, assetInfo, assetTree)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(assetTree.Root(), expected) {
+			return fmt.Errorf("asset tree %d mismatches the asset root at block %d", index, blockHeight)
+		}
+		version := assetTree.LatestVersion()
+		_, err = assetTree.Commit(&version)
+		if err != nil {
+			return fmt.Errorf("unable to commit asset tree %d: %v", index, err)
+		}
+		logx.Infof("assets of asset tree %d are reset to block %d", index, blockHeight)
+	}
+	return nil
+}
+
+// resetAssets sets the leaves of the asset tree to the assets in assetInfo. The assets of the
+// account in the account table are the ones it ever had, the leaves of those not in assetInfo
+// are set to empty.
+func resetAssets(accountModel account.AccountModel, index int64, assetInfo string, assetTree bsmt.SparseMerkleTree) error {
+	latest, err := accountModel.GetAccountByIndex(index)
+	if err != nil {
+		return fmt.Errorf("unable to get account %d: %v", index, err)
+	}
+	latestInfo, err := chain.ToFormatAccountInfo(latest)
+	if err != nil {
+		return err
+	}
+	info, err := chain.ToFormatAccountInfo(&account.Account{AssetInfo: assetInfo})
+	if err != nil {
+		return err
+	}
+	for assetId := range latestInfo.AssetInfo {
+		if _, ok := info.AssetInfo[assetId]; ok {
+			continue
+		}
+		err = assetTree.Set(uint64(assetId), NilAccountAssetNodeHash)
+		if err != nil {
+			return fmt.Errorf("unable to set asset to tree: %v", err)
+		}
+	}
+	for assetId, asset := range info.AssetInfo {
+		hashVal, err := AssetToNode(asset.Balance.String(), asset.OfferCanceledOrFinalized.String())
+		if err != nil {
+			return err
+		}
+		err = assetTree.Set(uint64(assetId), hashVal)
+		if err != nil {
+			return fmt.Errorf("unable to set asset to tree: %v", err)
+		}
+	}
+	return nil
+}
+
+// ReplayTrees applies the account and nft histories of the blocks after fromHeight up to
+// toHeight to the trees. The trees are committed once per block, so the versions of the
+// account and nft trees keep matching the block heights.
+func ReplayTrees(
+	accountModel account.AccountModel,
+	accountHistoryModel account.AccountHistoryModel,
+	nftHistoryModel nft.L2NftHistoryModel,
+	fromHeight, toHeight int64,
+	batchSize int,
+	accountTree bsmt.SparseMerkleTree,
+	accountAssetTrees *AssetTreeCache,
+	nftTree bsmt.SparseMerkleTree,
+) error {
+	accountInfos := make(map[int64]*account.Account)
+	for start := fromHeight; start < toHeight; start += int64(batchSize) {
+		end := start + int64(batchSize)
+		if end > toHeight {
+			end = toHeight
+		}
+		accountHistories, err := accountHistoryModel.GetAccountHistoriesByHeightRange(start, end)
+		if err != nil {
+			return fmt.Errorf("unable to get account histories from %d to %d: %v", start, end, err)
+		}
+		nftHistories, err := nftHistoryModel.GetNftHistoriesByHeightRange(start, end)
+		if err != nil {
+			return fmt.Errorf("unable to get nft histories from %d to %d: %v", start, end, err)
+		}
+
+		for height := start + 1; height <= end; height++ {
+			changed := make(map[int64]*account.AccountHistory)
+			for len(accountHistories) > 0 && accountHistories[0].L2BlockHeight == height {
+				changed[accountHistories[0].AccountIndex] = accountHistories[0]
+				accountHistories = accountHistories[1:]
+			}
+			for index, accountHistory := range changed {
+				accountInfo, err := replayAccountInfo(accountModel, accountInfos, accountHistory)
+				if err != nil {
+					return err
+				}
+				err = setAccount(accountInfo, accountTree, accountAssetTrees)
+				if err != nil {
+					return err
+				}
+				// Commit the asset tree right away, so the changes are not lost if it is evicted
+				// from the cache by the next accounts.
+				assetTree := accountAssetTrees.Get(index)
+				if common.Bytes2Hex(assetTree.Root()) != accountHistory.AssetRoot {
+					return fmt.Errorf("asset tree %d mismatches the asset root at block %d", index, height)
+				}
+				version := assetTree.LatestVersion()
+				_, err = assetTree.Commit(&version)
+				if err != nil {
+					return fmt.Errorf("unable to commit asset tree %d: %v", index, err)
+				}
+			}
+			for len(nftHistories) > 0 && nftHistories[0].L2BlockHeight == height {
+				hashVal, err := NftAssetToNode(nftHistories[0])
+				if err != nil {
+					return err
+				}
+				err = nftTree.Set(uint64(nftHistories[0].NftIndex), hashVal)
+				if err != nil {
+					return fmt.Errorf("unable to set nft %d to tree: %v", nftHistories[0].NftIndex, err)
+				}
+				nftHistories = nftHistories[1:]
+			}
+
+			_, err = accountTree.Commit(nil)
+			if err != nil {
+				return fmt.Errorf("unable to commit account tree at block %d: %v", height, err)
+			}
+			_, err = nftTree.Commit(nil)
+			if err != nil {
+				return fmt.Errorf("unable to commit nft tree at block %d: %v", height, err)
+			}
+		}
+		logx.Infof("replayed the trees to block %d", end)
+	}
+	return nil
+}
+
+func replayAccountInfo(accountModel account.AccountModel, accountInfos map[int64]*account.Account,
+	accountHistory *account.AccountHistory) (*account.Account, error) {
+	accountInfo := accountInfos[accountHistory.AccountIndex]
+	if accountInfo == nil {
+		info, err := accountModel.GetAccountByIndex(accountHistory.AccountIndex)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get account %d: %v", accountHistory.AccountIndex, err)
+		}
+		accountInfo = &account.Account{
+			AccountIndex:    info.AccountIndex,
+			AccountName:     info.AccountName,
+			PublicKey:       info.PublicKey,
+			AccountNameHash: info.AccountNameHash,
+			L1Address:       info.L1Address,
+			Status:          account.AccountStatusConfirmed,
+		}
+		accountInfos[accountHistory.AccountIndex] = accountInfo
+	}
+	accountInfo.Nonce = accountHistory.Nonce
+	accountInfo.CollectionNonce = accountHistory.CollectionNonce
+	accountInfo.AssetInfo = accountHistory.AssetInfo
+	accountInfo.AssetRoot = accountHistory.AssetRoot
+	return accountInfo, nil
+}
@@ -0,0 +1,127 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// stateRootBlockModel serves blocks with only the state roots set.
+type stateRootBlockModel struct {
+	block.BlockModel
+	stateRoots map[int64]string
+}
+
+func (m *stateRootBlockModel) GetBlockByHeightWithoutTx(blockHeight int64) (*block.Block, error) {
+	stateRoot, ok := m.stateRoots[blockHeight]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	return &block.Block{BlockHeight: blockHeight, StateRoot: stateRoot}, nil
+}
+
+func TestRollbackToConsistentVersion(t *testing.T) {
+	ctx, err := NewContext("test", BadgerDB, false, 0, nil, nil, &BadgerDBOption{File: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(ctx))
+	defer ctx.TreeDB.Close()
+	accountTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), SetNamespace(ctx, AccountPrefix),
+		AccountTreeHeight, NilAccountNodeHash, ctx.Options(0)...)
+	require.NoError(t, err)
+	nftTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), SetNamespace(ctx, NFTPrefix),
+		NftTreeHeight, NilNftNodeHash, ctx.Options(0)...)
+	require.NoError(t, err)
+
+	blockModel := &stateRootBlockModel{stateRoots: map[int64]string{0: common.Bytes2Hex(NilStateRoot)}}
+	for height := int64(1); height <= 3; height++ {
+		require.NoError(t, accountTree.Set(uint64(height), NilAccountAssetNodeHash))
+		require.NoError(t, nftTree.Set(uint64(height), NilAccountAssetNodeHash))
+		_, err = accountTree.Commit(nil)
+		require.NoError(t, err)
+		_, err = nftTree.Commit(nil)
+		require.NoError(t, err)
+		blockModel.stateRoots[height] = common.Bytes2Hex(ComputeStateRootHash(accountTree.Root(), nftTree.Root()))
+	}
+	version2Root := blockModel.stateRoots[2]
+	// block 3 was rolled back and produced again with other txs
+	blockModel.stateRoots[3] = common.Bytes2Hex(NilStateRoot)
+
+	version, err := RollbackToConsistentVersion(blockModel, 4, accountTree, nftTree)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.Equal(t, bsmt.Version(2), accountTree.LatestVersion())
+	assert.Equal(t, bsmt.Version(2), nftTree.LatestVersion())
+	assert.Equal(t, version2Root, common.Bytes2Hex(ComputeStateRootHash(accountTree.Root(), nftTree.Root())))
+
+	blockModel.stateRoots = map[int64]string{}
+	_, err = RollbackToConsistentVersion(blockModel, 2, accountTree, nftTree)
+	assert.Error(t, err)
+}
+
+// assetHistoryModel serves the asset info of one account at one height.
+type assetHistoryModel struct {
+	account.AccountHistoryModel
+	account.AccountModel
+	history *account.AccountHistory
+	latest  *account.Account
+}
+
+func (m *assetHistoryModel) GetAccountIndexesChangedAfter(height int64) ([]int64, error) {
+	return []int64{m.history.AccountIndex}, nil
+}
+
+func (m *assetHistoryModel) GetLatestAccountHistory(accountIndex, height int64) (*account.AccountHistory, error) {
+	return m.history, nil
+}
+
+func (m *assetHistoryModel) GetAccountByIndex(accountIndex int64) (*account.Account, error) {
+	return m.latest, nil
+}
+
+func TestRestoreAssetTrees(t *testing.T) {
+	ctx, err := NewContext("test", BadgerDB, false, 0, nil, nil, &BadgerDBOption{File: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(ctx))
+	defer ctx.TreeDB.Close()
+	assetTrees := NewLazyTreeCache(10, 1, 0, func(index, block int64) bsmt.SparseMerkleTree {
+		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), SetNamespace(ctx, AccountAssetNamespace(index)),
+			AssetTreeHeight, NilAccountAssetNodeHash, ctx.Options(block)...)
+		require.NoError(t, err)
+		return tree
+	})
+	setAsset := func(assetId uint64, balance string) {
+		hashVal, err := AssetToNode(balance, "0")
+		require.NoError(t, err)
+		assetTree := assetTrees.Get(0)
+		require.NoError(t, assetTree.Set(assetId, hashVal))
+		version := assetTree.LatestVersion()
+		_, err = assetTree.Commit(&version)
+		require.NoError(t, err)
+	}
+
+	// the asset tree is changed in blocks 1 to 3, and the trees are rolled back to block 1
+	setAsset(1, "10")
+	expected := common.Bytes2Hex(assetTrees.Get(0).Root())
+	setAsset(1, "20")
+	setAsset(2, "5")
+
+	models := &assetHistoryModel{
+		history: &account.AccountHistory{AccountIndex: 0, AssetRoot: expected,
+			AssetInfo: `{"1":{"AssetId":1,"Balance":10,"OfferCanceledOrFinalized":0}}`},
+		latest: &account.Account{AccountIndex: 0,
+			AssetInfo: `{"1":{"AssetId":1,"Balance":20,"OfferCanceledOrFinalized":0},"2":{"AssetId":2,"Balance":5,"OfferCanceledOrFinalized":0}}`},
+	}
+	require.NoError(t, RestoreAssetTrees(models, models, 1, assetTrees))
+	assert.Equal(t, expected, common.Bytes2Hex(assetTrees.Get(0).Root()))
+
+	// the asset tree matches already
+	require.NoError(t, RestoreAssetTrees(models, models, 1, assetTrees))
+	assert.Equal(t, expected, common.Bytes2Hex(assetTrees.Get(0).Root()))
+}
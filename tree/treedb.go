@@ -134,6 +134,7 @@ const (
 	MemoryDB Driver = "memorydb"
 	LevelDB  Driver = "leveldb"
 	RedisDB  Driver = "redis"
+	BadgerDB Driver = "badgerdb"
 )
 
 func SetupTreeDB(
@@ -150,6 +151,13 @@ func SetupTreeDB(
 		}
 		context.TreeDB = db
 		return nil
+	case BadgerDB:
+		db, err := NewBadgerDB(context.BadgerDBOption, false)
+		if err != nil {
+			return err
+		}
+		context.TreeDB = db
+		return nil
 	case RedisDB:
 		bytes, err := json.Marshal(context.RedisDBOption)
 		if err != nil {
@@ -179,6 +187,8 @@ func SetNamespace(
 		return memory.NewMemoryDB()
 	case LevelDB:
 		return leveldb.WrapWithNamespace(context.TreeDB.(*leveldb.Database), strings.Join([]string{context.Name, namespace}, ":"))
+	case BadgerDB:
+		return WrapBadgerDBWithNamespace(context.TreeDB.(*BadgerDatabase), strings.Join([]string{context.Name, namespace}, ":"))
 	case RedisDB:
 		return redis.WrapWithNamespace(context.TreeDB.(*redis.Database), strings.Join([]string{context.Name, namespace}, ":"))
 	}
@@ -193,7 +203,8 @@ func NewContext(
 	name string, driver Driver,
 	reload bool, routineSize int,
 	levelDBOption *LevelDBOption,
-	redisDBOption *RedisDBOption) (*Context, error) {
+	redisDBOption *RedisDBOption,
+	badgerDBOption *BadgerDBOption) (*Context, error) {
 
 	if routineSize <= 0 {
 		routineSize = defaultTreeRoutinePoolSize
@@ -207,6 +218,7 @@ func NewContext(
 		Driver:         driver,
 		LevelDBOption:  levelDBOption,
 		RedisDBOption:  redisDBOption,
+		BadgerDBOption: badgerDBOption,
 		reload:         reload,
 		routinePool:    pool,
 		hasher:         bsmt.NewHasherPool(func() hash.Hash { return mimc.NewMiMC() }),
@@ -215,10 +227,11 @@ func NewContext(
 }
 
 type Context struct {
-	Name          string
-	Driver        Driver
-	LevelDBOption *LevelDBOption
-	RedisDBOption *RedisDBOption
+	Name           string
+	Driver         Driver
+	LevelDBOption  *LevelDBOption
+	RedisDBOption  *RedisDBOption
+	BadgerDBOption *BadgerDBOption
 
 	TreeDB          database.TreeDB
 	defaultOptions  []bsmt.Option
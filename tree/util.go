@@ -87,8 +87,22 @@ func EmptyNftNodeHash() []byte {
 	return hFunc.Sum(nil)
 }
 
+// PrunedVersion returns the version the account and nft trees are pruned to, which keeps the
+// versions from retainedBlocks blocks before the latest verified block. The trees can be rolled
+// back to any kept version, and the witness never rolls back below the latest verified block.
+func PrunedVersion(latestVerifiedHeight int64, retainedBlocks int64) uint64 {
+	if retainedBlocks < 0 || latestVerifiedHeight <= retainedBlocks {
+		return 0
+	}
+	return uint64(latestVerifiedHeight - retainedBlocks)
+}
+
+// CommitTrees commits the changes of the trees and prunes the account and nft tree versions
+// before prunedVersion. Asset trees are versioned by their own commits and only keep the
+// previous version, so they are not rolled back along with the account tree, but restored
+// from the account history with RestoreAssetTrees.
 func CommitTrees(
-	version uint64,
+	prunedVersion uint64,
 	accountTree bsmt.SparseMerkleTree,
 	assetTrees *AssetTreeCache,
 	nftTree bsmt.SparseMerkleTree) error {
@@ -101,10 +115,14 @@ func CommitTrees(
 	defer close(errChan)
 
 	err := gopool.Submit(func() {
-		accPrunedVersion := bsmt.Version(version)
+		accPrunedVersion := bsmt.Version(prunedVersion)
 		if accountTree.LatestVersion() < accPrunedVersion {
 			accPrunedVersion = accountTree.LatestVersion()
 		}
+		// pruned versions cannot be restored
+		if accPrunedVersion < accountTree.RecentVersion() {
+			accPrunedVersion = accountTree.RecentVersion()
+		}
 		ver, err := accountTree.Commit(&accPrunedVersion)
 		if err != nil {
 			errChan <- errors.Wrapf(err, "unable to commit account tree, tree ver: %d, prune ver: %d", ver, accPrunedVersion)
@@ -135,10 +153,14 @@ func CommitTrees(
 	}
 
 	err = gopool.Submit(func() {
-		nftPrunedVersion := bsmt.Version(version)
+		nftPrunedVersion := bsmt.Version(prunedVersion)
 		if nftTree.LatestVersion() < nftPrunedVersion {
 			nftPrunedVersion = nftTree.LatestVersion()
 		}
+		// pruned versions cannot be restored
+		if nftPrunedVersion < nftTree.RecentVersion() {
+			nftPrunedVersion = nftTree.RecentVersion()
+		}
 		ver, err := nftTree.Commit(&nftPrunedVersion)
 		if err != nil {
 			errChan <- errors.Wrapf(err, "unable to commit nft tree, tree ver: %d, prune ver: %d", ver, nftPrunedVersion)
@@ -168,9 +190,10 @@ func RollBackTrees(
 
 	assetTreeChanges := assetTrees.GetChanges()
 	defer assetTrees.CleanChanges()
-	totalTask := len(assetTreeChanges) + 3
+	// the account tree, the changed asset trees and the nft tree, errChan is buffered for all of
+	// them and left open, so the tasks still running after an early return never block or panic
+	totalTask := len(assetTreeChanges) + 2
 	errChan := make(chan error, totalTask)
-	defer close(errChan)
 
 	ver := bsmt.Version(version)
 	err := gopool.Submit(func() {
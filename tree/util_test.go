@@ -0,0 +1,47 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+)
+
+func TestPrunedVersion(t *testing.T) {
+	assert.Equal(t, uint64(0), PrunedVersion(0, 0))
+	assert.Equal(t, uint64(10), PrunedVersion(10, 0))
+	assert.Equal(t, uint64(7), PrunedVersion(10, 3))
+	assert.Equal(t, uint64(0), PrunedVersion(10, 10))
+	assert.Equal(t, uint64(0), PrunedVersion(10, 20))
+}
+
+func TestCommitTreesKeepsPrunedVersion(t *testing.T) {
+	ctx, err := NewContext("test", MemoryDB, false, 0, nil, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(ctx))
+	accountTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), SetNamespace(ctx, AccountPrefix),
+		AccountTreeHeight, NilAccountNodeHash, ctx.Options(0)...)
+	require.NoError(t, err)
+	nftTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(), SetNamespace(ctx, NFTPrefix),
+		NftTreeHeight, NilNftNodeHash, ctx.Options(0)...)
+	require.NoError(t, err)
+	assetTrees := NewLazyTreeCache(10, 0, 0, func(index, block int64) bsmt.SparseMerkleTree {
+		tree, err := NewMemAccountAssetTree()
+		require.NoError(t, err)
+		return tree
+	})
+
+	for height := 1; height <= 5; height++ {
+		require.NoError(t, accountTree.Set(uint64(height), NilAccountNodeHash))
+		require.NoError(t, CommitTrees(PrunedVersion(3, 0), accountTree, assetTrees, nftTree))
+	}
+	assert.Equal(t, bsmt.Version(5), accountTree.LatestVersion())
+	assert.Equal(t, bsmt.Version(3), accountTree.RecentVersion())
+
+	// the verified height is unknown, the pruned versions are not restored
+	require.NoError(t, CommitTrees(0, accountTree, assetTrees, nftTree))
+	assert.Equal(t, bsmt.Version(3), accountTree.RecentVersion())
+	assert.Equal(t, bsmt.Version(3), nftTree.RecentVersion())
+}
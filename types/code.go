@@ -37,6 +37,10 @@ var (
 	DbErrFailToCreateNftHistory      = errors.New("fail to create nft history")
 	DbErrFailToCreatePriorityRequest = errors.New("fail to create priority request")
 	DbErrFailToUpdatePriorityRequest = errors.New("fail to update priority request")
+	DbErrFailToUpdateBlockWitness    = errors.New("fail to update block witness")
+	DbErrFailToCreateWithdrawal      = errors.New("fail to create withdrawal")
+	DbErrFailToUpdateWithdrawal      = errors.New("fail to update withdrawal")
+	DbErrFailToCreateCollection      = errors.New("fail to create collection")
 
 	JsonErrUnmarshal = errors.New("json.Unmarshal err")
 	JsonErrMarshal   = errors.New("json.Marshal err")
@@ -66,6 +70,7 @@ var (
 	AppErrInvalidGasFeeAccount         = New(21104, "invalid gas fee account")
 	AppErrInvalidToAccountNameHash     = New(21105, "invalid ToAccountNameHash")
 	AppErrAccountNameAlreadyRegistered = New(21106, "invalid account name, already registered")
+	AppErrInvalidAccountName           = New(21107, "invalid account name")
 
 	// Asset
 	AppErrAssetNotFound      = New(21200, "asset not found")
@@ -110,6 +115,7 @@ var (
 	AppErrInvalidCollectionId   = New(21700, "invalid collection id")
 	AppErrInvalidCollectionName = New(21701, "invalid collection name")
 	AppErrInvalidIntroduction   = New(21702, "invalid introduction")
+	AppErrCollectionNotFound    = New(21703, "collection not found")
 
 	AppErrInvalidGasAsset = New(25003, "invalid gas asset")
 	AppErrInvalidTxType   = New(25004, "invalid tx type")